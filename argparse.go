@@ -0,0 +1,248 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var pythonIdentifierRe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Argparse renders a Python module with a build_parser function, that builds an argparse.ArgumentParser
+// from the synopsis tree and the documented options:
+//
+//   - commands become subparsers, e.g. "ship new" is the new subparser of the ship subparser
+//   - options get their short and long flags, help from the description and default from [default: ...],
+//     typed when the default is an int or a float, and choices from [choices: ...]
+//   - options bound to an environment variable default to it
+//   - hidden options are suppressed from the help, and deprecated options say so in their help
+//   - repeated arguments, e.g. <name>..., get nargs
+//   - alternatives of options, e.g. [--moored|--drifting], become mutually exclusive groups
+//
+// -h and --help are left to argparse.
+func (d *DocOpt) Argparse() (string, error) {
+	root, err := d.commandTree()
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString("# Code generated by ronn2docopt. DO NOT EDIT.\n")
+	buffer.WriteString("\n")
+	buffer.WriteString("import argparse\n")
+	if root.hasEnv() {
+		buffer.WriteString("import os\n")
+	}
+	buffer.WriteString("\n")
+	buffer.WriteString("\n")
+	buffer.WriteString("def build_parser():\n")
+	buffer.WriteString("    parser = argparse.ArgumentParser(prog=" + pythonString(root.Name) + ")\n")
+
+	writeArgparseCommand(&buffer, root, "parser")
+
+	buffer.WriteString("\n")
+	buffer.WriteString("    return parser\n")
+
+	return buffer.String(), nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func writeArgparseCommand(buffer *bytes.Buffer, n *commandNode, parser string) {
+	prefix := strings.TrimSuffix(parser, "parser")
+
+	for i, g := range n.Groups {
+		group := prefix + "group_" + strconv.Itoa(i+1)
+
+		required := ""
+		if g.Required {
+			required = "required=True"
+		}
+
+		buffer.WriteString("    " + group + " = " + parser + ".add_mutually_exclusive_group(" + required + ")\n")
+	}
+
+	for _, o := range n.Options {
+		if isHelpOption(o) {
+			continue
+		}
+
+		target := parser
+		if o.Group != -1 {
+			target = prefix + "group_" + strconv.Itoa(o.Group+1)
+		}
+
+		args := quotePython(o.Flags)
+
+		switch {
+		case o.Value != "" && o.Repeated:
+			args = append(args, `action="append"`)
+		case o.Value == "" && o.Repeated:
+			args = append(args, `action="count"`, "default=0")
+		case o.Value == "":
+			args = append(args, `action="store_true"`)
+		}
+
+		if o.Value != "" {
+			args = append(args, "metavar="+pythonString(o.Value))
+		}
+
+		if o.Value != "" && len(o.Choices) > 0 {
+			args = append(args, "choices=["+strings.Join(quotePython(o.Choices), ", ")+"]")
+		}
+
+		// choices are strings, so the default has to be one too. Flags keep the default of their action
+		def := ""
+		switch kind := o.Default.Kind; {
+		case kind == NoDefault || o.Value == "":
+		case len(o.Choices) > 0:
+			def = pythonString(o.Default.Text)
+		case kind == IntDefault:
+			args = append(args, "type=int")
+			def = strconv.FormatInt(o.Default.Value.(int64), 10)
+		case kind == FloatDefault:
+			args = append(args, "type=float")
+			def = pythonFloat(o.Default.Value.(float64))
+		default:
+			def = pythonString(o.Default.Text)
+		}
+
+		// argparse converts string defaults with the type of the option, so the variable is typed too.
+		// Repeated options keep the default of their action
+		switch {
+		case o.Env == "" || o.Repeated:
+			if def != "" {
+				args = append(args, "default="+def)
+			}
+		case o.Value == "":
+			args = append(args, "default=bool(os.environ.get("+pythonString(o.Env)+"))")
+		case def == "":
+			args = append(args, "default=os.environ.get("+pythonString(o.Env)+")")
+		default:
+			args = append(args, "default=os.environ.get("+pythonString(o.Env)+", "+def+")")
+		}
+
+		switch {
+		case o.Hidden:
+			args = append(args, "help=argparse.SUPPRESS")
+		case o.Deprecated:
+			args = append(args, "help="+pythonHelp(strings.TrimSpace(o.Desc+" "+deprecationText(o))))
+		case o.Desc != "":
+			args = append(args, "help="+pythonHelp(o.Desc))
+		}
+
+		buffer.WriteString("    " + target + ".add_argument(" + strings.Join(args, ", ") + ")\n")
+	}
+
+	for _, a := range n.Arguments {
+		args := []string{pythonString(pythonIdentifier(a.Name)), "metavar=" + pythonString(a.Name)}
+
+		switch {
+		case a.Repeated && a.Optional:
+			args = append(args, `nargs="*"`)
+		case a.Repeated:
+			args = append(args, `nargs="+"`)
+		case a.Optional:
+			args = append(args, `nargs="?"`)
+		}
+
+		if a.Desc != "" {
+			args = append(args, "help="+pythonHelp(a.Desc))
+		}
+
+		buffer.WriteString("    " + parser + ".add_argument(" + strings.Join(args, ", ") + ")\n")
+	}
+
+	if len(n.Commands) == 0 {
+		return
+	}
+
+	subparsers := prefix + "subparsers"
+	dest := pythonIdentifier(strings.Join(append(n.Path[:len(n.Path):len(n.Path)], "command"), "_"))
+
+	buffer.WriteString("\n")
+	buffer.WriteString("    " + subparsers + " = " + parser + ".add_subparsers(dest=" + pythonString(dest) +
+		", metavar=\"<command>\"")
+	if !n.Runnable {
+		buffer.WriteString(", required=True")
+	}
+	buffer.WriteString(")\n")
+
+	for _, c := range n.Commands {
+		child := pythonIdentifier(strings.Join(c.Path, "_")) + "_parser"
+
+		args := []string{pythonString(c.Name)}
+		if c.Desc != "" {
+			args = append(args, "help="+pythonHelp(c.Desc))
+		}
+
+		buffer.WriteString("\n")
+		buffer.WriteString("    " + child + " = " + subparsers + ".add_parser(" + strings.Join(args, ", ") + ")\n")
+
+		writeArgparseCommand(buffer, c, child)
+	}
+}
+
+// e.g. (deprecated: use --speed)
+func deprecationText(o commandOption) string {
+	if o.Deprecation == "" {
+		return "(deprecated)"
+	}
+
+	return "(deprecated: " + o.Deprecation + ")"
+}
+
+func isHelpOption(o commandOption) bool {
+	for _, f := range o.Flags {
+		if f != "-h" && f != "--help" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// e.g. <file-name> returns file_name
+func pythonIdentifier(name string) string {
+	id := pythonIdentifierRe.ReplaceAllString(strings.ToLower(name), "_")
+	id = strings.Trim(id, "_")
+
+	if id == "" || (id[0] >= '0' && id[0] <= '9') {
+		id = "_" + id
+	}
+
+	return id
+}
+
+// e.g. 1e3 returns 1000.0, Python floats need a point or an exponent
+func pythonFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// Go and Python share the escapes strconv.Quote uses
+func pythonString(s string) string {
+	return strconv.Quote(s)
+}
+
+// argparse formats help with %, so literal percent signs are doubled
+func pythonHelp(desc string) string {
+	return pythonString(strings.Replace(PlainInline(desc), "%", "%%", -1))
+}
+
+func quotePython(strs []string) []string {
+	quoted := make([]string, len(strs))
+	for i, s := range strs {
+		quoted[i] = pythonString(s)
+	}
+
+	return quoted
+}
@@ -0,0 +1,476 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// the backtick of --verbose is not closed, so its declaration has no flags
+var noFlagsPage = []string{
+	"## SYNOPSIS",
+	"",
+	"`tool` `[options] <file>`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `--verbose:",
+	"    Be verbose.",
+	"  * `--quiet`:",
+	"    Be quiet.",
+	"",
+}
+
+// The value of -D is written with a space, like in the usage of ronn2docopt itself
+var optionValuePage = []string{
+	"## SYNOPSIS",
+	"",
+	"`tool` `[-D <var>]... <file>`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `-D <var>`:",
+	"    Set a variable.",
+	"",
+}
+
+var envPage = []string{
+	"## SYNOPSIS",
+	"",
+	"`tool` `[options] <file>`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `--speed=<kn>`:",
+	"    Speed in knots. [default: 10]",
+	"  * `--name=<name>`:",
+	"    Name of the ship.",
+	"  * `--color`:",
+	"    Colorize the output.",
+	"",
+	"## ENVIRONMENT",
+	"",
+	"  * `TOOL_SPEED`:",
+	"    Overrides --speed.",
+	"  * `TOOL_NAME`:",
+	"    Overrides --name.",
+	"  * `TOOL_COLOR`:",
+	"    Overrides --color.",
+	"",
+}
+
+func TestDocOpt_Argparse(t *testing.T) {
+	t.Run("when the synopsis has commands", func(t *testing.T) {
+		got, err := RonnToDocopt(exampleFile).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"naval_fate\")\n" +
+			"    parser.add_argument(\"--version\", action=\"store_true\", help=\"Show version.\")\n" +
+			"\n" +
+			"    subparsers = parser.add_subparsers(dest=\"command\", metavar=\"<command>\")\n" +
+			"\n" +
+			"    ship_parser = subparsers.add_parser(\"ship\")\n" +
+			"\n" +
+			"    ship_subparsers = ship_parser.add_subparsers(dest=\"ship_command\", metavar=\"<command>\", required=True)\n" +
+			"\n" +
+			"    ship_new_parser = ship_subparsers.add_parser(\"new\")\n" +
+			"    ship_new_parser.add_argument(\"name\", metavar=\"<name>\", nargs=\"+\")\n" +
+			"\n" +
+			"    ship_move_parser = ship_subparsers.add_parser(\"move\")\n" +
+			"    ship_move_parser.add_argument(\"--speed\", metavar=\"<kn>\", type=int, default=10, help=\"Speed in knots.\")\n" +
+			"    ship_move_parser.add_argument(\"name\", metavar=\"<name>\")\n" +
+			"    ship_move_parser.add_argument(\"x\", metavar=\"<x>\")\n" +
+			"    ship_move_parser.add_argument(\"y\", metavar=\"<y>\")\n" +
+			"\n" +
+			"    ship_shoot_parser = ship_subparsers.add_parser(\"shoot\")\n" +
+			"    ship_shoot_parser.add_argument(\"x\", metavar=\"<x>\")\n" +
+			"    ship_shoot_parser.add_argument(\"y\", metavar=\"<y>\")\n" +
+			"\n" +
+			"    mine_parser = subparsers.add_parser(\"mine\")\n" +
+			"\n" +
+			"    mine_subparsers = mine_parser.add_subparsers(dest=\"mine_command\", metavar=\"<command>\", required=True)\n" +
+			"\n" +
+			"    mine_set_parser = mine_subparsers.add_parser(\"set\")\n" +
+			"    mine_set_group_1 = mine_set_parser.add_mutually_exclusive_group()\n" +
+			"    mine_set_group_1.add_argument(\"--moored\", action=\"store_true\")\n" +
+			"    mine_set_group_1.add_argument(\"--drifting\", action=\"store_true\")\n" +
+			"    mine_set_parser.add_argument(\"x\", metavar=\"<x>\")\n" +
+			"    mine_set_parser.add_argument(\"y\", metavar=\"<y>\")\n" +
+			"\n" +
+			"    mine_remove_parser = mine_subparsers.add_parser(\"remove\")\n" +
+			"    mine_remove_group_1 = mine_remove_parser.add_mutually_exclusive_group()\n" +
+			"    mine_remove_group_1.add_argument(\"--moored\", action=\"store_true\")\n" +
+			"    mine_remove_group_1.add_argument(\"--drifting\", action=\"store_true\")\n" +
+			"    mine_remove_parser.add_argument(\"x\", metavar=\"<x>\")\n" +
+			"    mine_remove_parser.add_argument(\"y\", metavar=\"<y>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when the synopsis has required alternatives and optional arguments", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`copy` `(--move|--link) [-v...] <src> [<dst>]`<br>",
+			"`copy` `--list`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `--move`:",
+			"    Move the file, 100% of the time.",
+			"  * `--link`:",
+			"    Link the file.",
+			"  * `-v`:",
+			"    More output.",
+			"",
+		}
+
+		got, err := RonnToDocopt(lines).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"copy\")\n" +
+			"    group_1 = parser.add_mutually_exclusive_group(required=True)\n" +
+			"    group_1.add_argument(\"--move\", action=\"store_true\", help=\"Move the file, 100%% of the time.\")\n" +
+			"    group_1.add_argument(\"--link\", action=\"store_true\", help=\"Link the file.\")\n" +
+			"    parser.add_argument(\"-v\", action=\"count\", default=0, help=\"More output.\")\n" +
+			"    parser.add_argument(\"--list\", action=\"store_true\")\n" +
+			"    parser.add_argument(\"src\", metavar=\"<src>\", nargs=\"?\")\n" +
+			"    parser.add_argument(\"dst\", metavar=\"<dst>\", nargs=\"?\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when flags have defaults", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`thing` `[-v]... [--quiet] <file>`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `-v`:",
+			"    Be verbose. [default: 0]",
+			"  * `--quiet`:",
+			"    Be quiet. [default: false]",
+			"",
+		}
+
+		got, err := RonnToDocopt(lines).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"thing\")\n" +
+			"    parser.add_argument(\"-v\", action=\"count\", default=0, help=\"Be verbose.\")\n" +
+			"    parser.add_argument(\"--quiet\", action=\"store_true\", help=\"Be quiet.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when int and float defaults are not Python literals", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`thing` `[--speed=<kn>] [--ratio=<r>]`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots. [default: 010]",
+			"  * `--ratio=<r>`:",
+			"    Ratio. [default: 1e3]",
+			"",
+		}
+
+		got, err := RonnToDocopt(lines).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range []string{"type=int, default=10,", "type=float, default=1000.0,"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("parser doesn't contain %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("when options have choices", func(t *testing.T) {
+		got, err := RonnToDocopt(choicesPage).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"render\")\n" +
+			"    parser.add_argument(\"-f\", \"--format\", metavar=\"<fmt>\", choices=[\"json\", \"yaml\", \"text\"], default=\"json\", help=\"Output format\")\n" +
+			"    parser.add_argument(\"--level\", metavar=\"<n>\", choices=[\"1\", \"2\", \"3\"], default=\"2\", help=\"Compression level.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are deprecated or hidden", func(t *testing.T) {
+		got, err := RonnToDocopt(annotatedPage).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"thing\")\n" +
+			"    parser.add_argument(\"--speed\", metavar=\"<kn>\", help=\"Speed in knots.\")\n" +
+			"    parser.add_argument(\"--velocity\", metavar=\"<kn>\", help=\"Speed in knots. (deprecated: use --speed)\")\n" +
+			"    parser.add_argument(\"--debug\", action=\"store_true\", help=argparse.SUPPRESS)\n" +
+			"    parser.add_argument(\"--trace\", action=\"store_true\", help=argparse.SUPPRESS)\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are repeatable", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`thing` `[-v] [--tag=<tag>] [options] <file>`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `-v`:",
+			"    Be verbose. [repeatable]",
+			"  * `--tag=<tag>`:",
+			"    Tag the file. [repeatable]",
+			"  * `--exclude=<dir>`:",
+			"    Exclude a directory. [repeatable]",
+			"",
+		}
+
+		got, err := RonnToDocopt(lines).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"thing\")\n" +
+			"    parser.add_argument(\"-v\", action=\"count\", default=0, help=\"Be verbose.\")\n" +
+			"    parser.add_argument(\"--tag\", action=\"append\", metavar=\"<tag>\", help=\"Tag the file.\")\n" +
+			"    parser.add_argument(\"--exclude\", action=\"append\", metavar=\"<dir>\", help=\"Exclude a directory.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when an option declaration has no flags", func(t *testing.T) {
+		got, err := RonnToDocopt(noFlagsPage).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"tool\")\n" +
+			"    parser.add_argument(\"--quiet\", action=\"store_true\", help=\"Be quiet.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when an option value is written with a space", func(t *testing.T) {
+		got, err := RonnToDocopt(optionValuePage).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"tool\")\n" +
+			"    parser.add_argument(\"-D\", action=\"append\", metavar=\"<var>\", help=\"Set a variable.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are bound to environment variables", func(t *testing.T) {
+		got, err := RonnToDocopt(envPage).Argparse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"import argparse\n" +
+			"import os\n" +
+			"\n" +
+			"\n" +
+			"def build_parser():\n" +
+			"    parser = argparse.ArgumentParser(prog=\"tool\")\n" +
+			"    parser.add_argument(\"--speed\", metavar=\"<kn>\", type=int, default=os.environ.get(\"TOOL_SPEED\", 10), help=\"Speed in knots.\")\n" +
+			"    parser.add_argument(\"--name\", metavar=\"<name>\", default=os.environ.get(\"TOOL_NAME\"), help=\"Name of the ship.\")\n" +
+			"    parser.add_argument(\"--color\", action=\"store_true\", default=bool(os.environ.get(\"TOOL_COLOR\")), help=\"Colorize the output.\")\n" +
+			"    parser.add_argument(\"file\", metavar=\"<file>\")\n" +
+			"\n" +
+			"    return parser\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when the synopsis can't be parsed", func(t *testing.T) {
+		lines := []string{"## SYNOPSIS", "", "`copy` `[<src>`", ""}
+
+		if _, err := RonnToDocopt(lines).Argparse(); err == nil {
+			t.Error("err got = nil, want an error")
+		}
+	})
+}
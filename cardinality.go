@@ -0,0 +1,135 @@
+package ronn2docopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Cardinality is how many times an option can be given
+type Cardinality int
+
+const (
+	SingleOption Cardinality = iota
+
+	// a flag that can be repeated, e.g. -v..., and is counted
+	CountedOption
+
+	// an option with a value that can be repeated, e.g. --tag=<tag>..., and collects the values
+	RepeatedOption
+)
+
+var cardinalityNames = []string{"single", "counted", "repeated"}
+
+func (c Cardinality) String() string {
+	return cardinalityNames[c]
+}
+
+func (c Cardinality) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// An option that can be repeated is counted when it's a flag, and collects its values otherwise
+func repeatedCardinality(name string) Cardinality {
+	if optionTakesValue(name) {
+		return RepeatedOption
+	}
+
+	return CountedOption
+}
+
+// Sets the cardinality of the options repeated in the synopsis, e.g. -v... or [-v]...,
+// and repeats the options marked [repeatable] in the synopsis, so that docopt counts them too.
+func (d *DocOpt) linkCardinality() []Diagnostic {
+	var diagnostics []Diagnostic
+
+	usages, err := ParseSynopsis(d.Synopsis)
+	if err != nil {
+		return diagnostics
+	}
+
+	repeated := map[string]bool{}
+	used := map[string]bool{}
+	shortcut := false
+
+	for _, u := range usages {
+		walkOptions(u.Pattern, false, func(leaf *Pattern, r bool) {
+			if leaf.Kind == OptionsShortcutPattern {
+				shortcut = true
+				return
+			}
+
+			for _, f := range optionFlags(leaf.Name) {
+				used[f] = true
+				repeated[f] = repeated[f] || r
+			}
+		})
+	}
+
+	for i := range d.HelpOptionSections {
+		for j := range d.HelpOptionSections[i].Options {
+			o := &d.HelpOptionSections[i].Options[j]
+
+			if o.usedIn(repeated) {
+				o.Cardinality = repeatedCardinality(o.Name)
+				continue
+			}
+
+			if o.Cardinality == SingleOption {
+				continue
+			}
+
+			if o.usedIn(used) {
+				d.Synopsis = repeatInSynopsis(d.Synopsis, *o)
+			} else if shortcut {
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:    o.Line,
+					Code:    "repeatable-option",
+					Message: fmt.Sprintf("option %s is repeatable, but only used through [options], where docopt accepts it once", o.Name),
+				})
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// Calls fn with the option leaves and options shortcuts of the pattern, and whether they are repeated
+func walkOptions(p *Pattern, repeated bool, fn func(*Pattern, bool)) {
+	repeated = repeated || p.Repeated
+
+	switch p.Kind {
+	case OptionPattern, OptionsShortcutPattern:
+		fn(p, repeated)
+	}
+
+	for _, c := range p.Children {
+		walkOptions(c, repeated, fn)
+	}
+}
+
+// Adds ... after every use of the option in the synopsis, e.g. [-v] becomes [-v...]
+func repeatInSynopsis(synopsis string, option HelpOption) string {
+	value := `(?:=[^\s\[\]()|]+)?`
+	if optionTakesValue(option.Name) {
+		value = `(?:=[^\s\[\]()|]+| <[^\s<>]+>)?`
+	}
+
+	for _, f := range optionFlags(option.Name) {
+		re := regexp.MustCompile(`(^|[\s\[(|])(` + regexp.QuoteMeta(f) + value + `)([\s\])|]|$)`)
+
+		var lines []string
+		for _, line := range strings.Split(synopsis, "\n") {
+			lines = append(lines, re.ReplaceAllString(line, "$1$2...$3"))
+		}
+
+		synopsis = strings.Join(lines, "\n")
+	}
+
+	return synopsis
+}
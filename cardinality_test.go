@@ -0,0 +1,88 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var repeatablePage = []string{
+	"## SYNOPSIS",
+	"",
+	"`thing` `[-v] [-q]... [-t <tag>] [--include=<dir>] <file>`<br>",
+	"`thing` `[options] (-v | --version)`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `-v`, `--verbose`:",
+	"    Be verbose. [repeatable]",
+	"  * `-q`:",
+	"    Be quiet.",
+	"  * `-t <tag>`:",
+	"    Tag the file. [repeatable]",
+	"  * `--include=<dir>`:",
+	"    Include a directory.",
+	"  * `--exclude=<dir>`:",
+	"    Exclude a directory. [repeatable]",
+	"  * `--version`:",
+	"    Show the version.",
+	"",
+}
+
+func TestCardinality_String(t *testing.T) {
+	for c, want := range map[Cardinality]string{SingleOption: "single", CountedOption: "counted", RepeatedOption: "repeated"} {
+		if got := c.String(); got != want {
+			t.Errorf("String() got = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRonnToDocopt_Cardinality(t *testing.T) {
+	d := RonnToDocopt(repeatablePage)
+
+	t.Run("reads cardinality from the synopsis and annotations", func(t *testing.T) {
+		var got []string
+		for _, s := range d.HelpOptionSections {
+			for _, o := range s.Options {
+				got = append(got, fmt.Sprintf("%s|%s|%s", o.Name, o.Desc, o.Cardinality))
+			}
+		}
+
+		want := []string{
+			"-v --verbose|Be verbose.|counted",
+			"-q|Be quiet.|counted",
+			"-t <tag>|Tag the file.|repeated",
+			"--include=<dir>|Include a directory.|single",
+			"--exclude=<dir>|Exclude a directory.|repeated",
+			"--version|Show the version.|single",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("options got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("repeats annotated options in the synopsis", func(t *testing.T) {
+		want := "  thing [-v...] [-q]... [-t <tag>...] [--include=<dir>] <file>\n" +
+			"  thing [options] (-v... | --version)"
+
+		if d.Synopsis != want {
+			t.Errorf("synopsis got = %q, want %q", d.Synopsis, want)
+		}
+	})
+
+	t.Run("when a repeatable option is only used through [options]", func(t *testing.T) {
+		var got []string
+		for _, diagnostic := range d.Diagnostics {
+			got = append(got, diagnostic.Code+" "+diagnostic.String())
+		}
+
+		want := []string{
+			"repeatable-option 16: option --exclude=<dir> is repeatable, but only used through [options], where docopt accepts it once",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("diagnostics got = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,383 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true, "continue": true, "dyn": true,
+	"else": true, "enum": true, "extern": true, "false": true, "fn": true, "for": true, "if": true, "impl": true,
+	"in": true, "let": true, "loop": true, "match": true, "mod": true, "move": true, "mut": true, "pub": true,
+	"ref": true, "return": true, "static": true, "struct": true, "trait": true, "true": true, "type": true,
+	"unsafe": true, "use": true, "where": true, "while": true, "abstract": true, "become": true, "box": true,
+	"do": true, "final": true, "macro": true, "override": true, "priv": true, "try": true, "typeof": true,
+	"unsized": true, "virtual": true, "yield": true,
+}
+
+// Clap renders Rust source for a clap derive parser: a Cli struct deriving Parser,
+// an enum deriving Subcommand for each command with subcommands, and a struct deriving Args for each command.
+// Descriptions become doc comments, and options get short, long and default_value attributes,
+// with an i64 or f64 field when the default is an int or a float, and choices become a value_parser.
+// Options bound to an environment variable get an env attribute, which needs the env feature of clap.
+// Hidden options are hidden from the help, and deprecated options say so in their doc comment.
+// Alternatives of options, e.g. [--moored|--drifting], become argument groups. -h and --help are left to clap.
+func (d *DocOpt) Clap() (string, error) {
+	root, err := d.commandTree()
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	writeClapCommand(&body, root)
+
+	// only import what's used, so the generated source compiles without warnings
+	imports := []string{}
+	for _, i := range []struct{ name, use string }{
+		{"ArgAction", "ArgAction::"},
+		{"ArgGroup", "ArgGroup::"},
+		{"Args", "derive(Args"},
+		{"Parser", "derive(Parser"},
+		{"Subcommand", "derive(Subcommand"},
+	} {
+		if strings.Contains(body.String(), i.use) {
+			imports = append(imports, i.name)
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString("// Code generated by ronn2docopt. DO NOT EDIT.\n")
+	buffer.WriteString("\n")
+	if len(imports) == 1 {
+		buffer.WriteString("use clap::" + imports[0] + ";\n")
+	} else {
+		buffer.WriteString("use clap::{" + strings.Join(imports, ", ") + "};\n")
+	}
+	buffer.Write(body.Bytes())
+
+	return buffer.String(), nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func writeClapCommand(buffer *bytes.Buffer, n *commandNode) {
+	name := clapTypeName(n.Path, "Args")
+
+	buffer.WriteString("\n")
+	writeRustDoc(buffer, "", n.Desc)
+
+	if len(n.Path) == 0 {
+		buffer.WriteString("#[derive(Parser, Debug)]\n")
+		buffer.WriteString("#[command(name = " + rustString(n.Name) + ")]\n")
+	} else {
+		buffer.WriteString("#[derive(Args, Debug)]\n")
+	}
+
+	for i, g := range n.Groups {
+		var ids []string
+		for _, o := range g.Options {
+			ids = append(ids, rustString(clapFieldName(n.Options[o])))
+		}
+
+		group := "ArgGroup::new(" + rustString(clapGroupID(i)) + ")"
+		if g.Required {
+			group += ".required(true)"
+		}
+
+		buffer.WriteString("#[command(group(" + group + ".args([" + strings.Join(ids, ", ") + "])))]\n")
+	}
+
+	buffer.WriteString("pub struct " + name + " {\n")
+
+	var fields []string
+
+	for _, o := range n.Options {
+		if isHelpOption(o) {
+			continue
+		}
+
+		var field bytes.Buffer
+		if o.Deprecated {
+			writeRustDoc(&field, "    ", strings.TrimSpace(o.Desc+" "+deprecationText(o)))
+		} else {
+			writeRustDoc(&field, "    ", o.Desc)
+		}
+
+		var attrs []string
+		for _, f := range o.Flags {
+			if strings.HasPrefix(f, "--") {
+				attrs = append(attrs, "long = "+rustString(strings.TrimPrefix(f, "--")))
+			} else if len(f) == 2 {
+				attrs = append(attrs, fmt.Sprintf("short = '%c'", f[1]))
+			}
+		}
+
+		fieldType := "bool"
+		switch {
+		case o.Value == "" && o.Repeated:
+			attrs = append(attrs, "action = ArgAction::Count")
+			fieldType = "u8"
+		case o.Value != "" && o.Repeated:
+			attrs = append(attrs, "value_name = "+rustString(clapValueName(o.Value)), "action = ArgAction::Append")
+			fieldType = "Vec<String>"
+		case o.Value != "":
+			attrs = append(attrs, "value_name = "+rustString(clapValueName(o.Value)))
+			fieldType = "Option<String>"
+		}
+
+		if o.Value != "" && len(o.Choices) > 0 {
+			var choices []string
+			for _, c := range o.Choices {
+				choices = append(choices, rustString(c))
+			}
+
+			attrs = append(attrs, "value_parser = ["+strings.Join(choices, ", ")+"]")
+		}
+
+		if o.Value != "" && o.Default.Kind != NoDefault {
+			attrs = append(attrs, "default_value = "+rustString(o.Default.Text))
+
+			// choices are strings, so the field has to be one too
+			if !o.Repeated {
+				fieldType = "String"
+				if len(o.Choices) == 0 {
+					fieldType = rustDefaultType(o.Default)
+				}
+			}
+		}
+
+		// counted flags can't be set from the environment
+		if o.Env != "" && (o.Value != "" || !o.Repeated) {
+			attrs = append(attrs, "env = "+rustString(o.Env))
+		}
+
+		if o.Hidden {
+			attrs = append(attrs, "hide = true")
+		}
+
+		field.WriteString("    #[arg(" + strings.Join(attrs, ", ") + ")]\n")
+		field.WriteString("    pub " + rustIdentifier(clapFieldName(o)) + ": " + fieldType + ",\n")
+
+		fields = append(fields, field.String())
+	}
+
+	for _, a := range n.Arguments {
+		var field bytes.Buffer
+		writeRustDoc(&field, "    ", a.Desc)
+
+		attrs := []string{"value_name = " + rustString(clapValueName(a.Name))}
+
+		fieldType := "String"
+		switch {
+		case a.Repeated:
+			fieldType = "Vec<String>"
+			if !a.Optional {
+				attrs = append(attrs, "required = true")
+			}
+		case a.Optional:
+			fieldType = "Option<String>"
+		}
+
+		field.WriteString("    #[arg(" + strings.Join(attrs, ", ") + ")]\n")
+		field.WriteString("    pub " + rustIdentifier(pythonIdentifier(a.Name)) + ": " + fieldType + ",\n")
+
+		fields = append(fields, field.String())
+	}
+
+	if len(n.Commands) > 0 {
+		fieldType := clapTypeName(n.Path, "Command")
+		if n.Runnable {
+			fieldType = "Option<" + fieldType + ">"
+		}
+
+		fields = append(fields, "    #[command(subcommand)]\n    pub command: "+fieldType+",\n")
+	}
+
+	buffer.WriteString(strings.Join(fields, "\n"))
+	buffer.WriteString("}\n")
+
+	if len(n.Commands) == 0 {
+		return
+	}
+
+	buffer.WriteString("\n")
+	buffer.WriteString("#[derive(Subcommand, Debug)]\n")
+	buffer.WriteString("pub enum " + clapTypeName(n.Path, "Command") + " {\n")
+
+	var variants []string
+	separator := ""
+	for _, c := range n.Commands {
+		var variant bytes.Buffer
+		writeRustDoc(&variant, "    ", c.Desc)
+
+		// clap names subcommands after their variant, in kebab case
+		if kebabCase(pascalCase(c.Name)) != c.Name {
+			variant.WriteString("    #[command(name = " + rustString(c.Name) + ")]\n")
+		}
+
+		// variants are separated by blank lines once any of them has a doc comment or attribute
+		if variant.Len() > 0 {
+			separator = "\n"
+		}
+
+		variant.WriteString("    " + pascalCase(c.Name) + "(" + clapTypeName(c.Path, "Args") + "),\n")
+
+		variants = append(variants, variant.String())
+	}
+
+	buffer.WriteString(strings.Join(variants, separator))
+	buffer.WriteString("}\n")
+
+	for _, c := range n.Commands {
+		writeClapCommand(buffer, c)
+	}
+}
+
+// Ints and floats keep their type, other defaults are parsed by the program
+func rustDefaultType(value Default) string {
+	switch value.Kind {
+	case IntDefault:
+		return "i64"
+	case FloatDefault:
+		return "f64"
+	}
+
+	return "String"
+}
+
+func writeRustDoc(buffer *bytes.Buffer, indent string, desc string) {
+	if desc == "" {
+		return
+	}
+
+	buffer.WriteString(indent + "/// " + PlainInline(desc) + "\n")
+}
+
+// e.g. [ship move] returns ShipMoveArgs, and the root returns Cli
+func clapTypeName(path []string, suffix string) string {
+	if len(path) == 0 && suffix == "Args" {
+		return "Cli"
+	}
+
+	var name string
+	for _, p := range path {
+		name += pascalCase(p)
+	}
+
+	return name + suffix
+}
+
+func clapGroupID(i int) string {
+	return fmt.Sprintf("group_%d", i+1)
+}
+
+// Fields are named after the long flag, or the short flag when there is none,
+// or the declaration when it has no flags
+func clapFieldName(o commandOption) string {
+	if len(o.Flags) == 0 {
+		return pythonIdentifier(o.Name)
+	}
+
+	name := o.Flags[0]
+	for _, f := range o.Flags {
+		if strings.HasPrefix(f, "--") {
+			name = f
+			break
+		}
+	}
+
+	return pythonIdentifier(name)
+}
+
+// e.g. <kn> returns KN
+func clapValueName(name string) string {
+	return strings.ToUpper(strings.Trim(name, "<>"))
+}
+
+// Keywords are written as raw identifiers, e.g. r#type
+func rustIdentifier(name string) string {
+	switch name {
+	case "self", "Self", "super", "crate":
+		return name + "_"
+	}
+
+	if rustKeywords[name] {
+		return "r#" + name
+	}
+
+	return name
+}
+
+func rustString(s string) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&buffer, `\u{%x}`, r)
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+
+	return buffer.String()
+}
+
+// e.g. dry-run returns DryRun
+func pascalCase(s string) string {
+	var buffer bytes.Buffer
+
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		buffer.WriteRune(r)
+	}
+
+	return buffer.String()
+}
+
+// e.g. DryRun returns dry-run
+func kebabCase(s string) string {
+	var buffer bytes.Buffer
+
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				buffer.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+
+		buffer.WriteRune(r)
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,410 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func TestDocOpt_Clap(t *testing.T) {
+	t.Run("when the synopsis has commands", func(t *testing.T) {
+		got, err := RonnToDocopt(exampleFile).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::{ArgGroup, Args, Parser, Subcommand};\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"naval_fate\")]\n" +
+			"pub struct Cli {\n" +
+			"    /// Show version.\n" +
+			"    #[arg(long = \"version\")]\n" +
+			"    pub version: bool,\n" +
+			"\n" +
+			"    #[command(subcommand)]\n" +
+			"    pub command: Option<Command>,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Subcommand, Debug)]\n" +
+			"pub enum Command {\n" +
+			"    Ship(ShipArgs),\n" +
+			"    Mine(MineArgs),\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"pub struct ShipArgs {\n" +
+			"    #[command(subcommand)]\n" +
+			"    pub command: ShipCommand,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Subcommand, Debug)]\n" +
+			"pub enum ShipCommand {\n" +
+			"    New(ShipNewArgs),\n" +
+			"    Move(ShipMoveArgs),\n" +
+			"    Shoot(ShipShootArgs),\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"pub struct ShipNewArgs {\n" +
+			"    #[arg(value_name = \"NAME\", required = true)]\n" +
+			"    pub name: Vec<String>,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"pub struct ShipMoveArgs {\n" +
+			"    /// Speed in knots.\n" +
+			"    #[arg(long = \"speed\", value_name = \"KN\", default_value = \"10\")]\n" +
+			"    pub speed: i64,\n" +
+			"\n" +
+			"    #[arg(value_name = \"NAME\")]\n" +
+			"    pub name: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"X\")]\n" +
+			"    pub x: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"Y\")]\n" +
+			"    pub y: String,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"pub struct ShipShootArgs {\n" +
+			"    #[arg(value_name = \"X\")]\n" +
+			"    pub x: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"Y\")]\n" +
+			"    pub y: String,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"pub struct MineArgs {\n" +
+			"    #[command(subcommand)]\n" +
+			"    pub command: MineCommand,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Subcommand, Debug)]\n" +
+			"pub enum MineCommand {\n" +
+			"    Set(MineSetArgs),\n" +
+			"    Remove(MineRemoveArgs),\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"#[command(group(ArgGroup::new(\"group_1\").args([\"moored\", \"drifting\"])))]\n" +
+			"pub struct MineSetArgs {\n" +
+			"    #[arg(long = \"moored\")]\n" +
+			"    pub moored: bool,\n" +
+			"\n" +
+			"    #[arg(long = \"drifting\")]\n" +
+			"    pub drifting: bool,\n" +
+			"\n" +
+			"    #[arg(value_name = \"X\")]\n" +
+			"    pub x: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"Y\")]\n" +
+			"    pub y: String,\n" +
+			"}\n" +
+			"\n" +
+			"#[derive(Args, Debug)]\n" +
+			"#[command(group(ArgGroup::new(\"group_1\").args([\"moored\", \"drifting\"])))]\n" +
+			"pub struct MineRemoveArgs {\n" +
+			"    #[arg(long = \"moored\")]\n" +
+			"    pub moored: bool,\n" +
+			"\n" +
+			"    #[arg(long = \"drifting\")]\n" +
+			"    pub drifting: bool,\n" +
+			"\n" +
+			"    #[arg(value_name = \"X\")]\n" +
+			"    pub x: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"Y\")]\n" +
+			"    pub y: String,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are grouped, counted or keywords", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`copy` `(--move|--link) [-v...] [--type=<type>] <src> [<dst>]`<br>",
+			"`copy` `--list`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `--move`:",
+			"    Move the file, \"safely\".",
+			"  * `--link`:",
+			"    Link the file.",
+			"  * `-v`:",
+			"    More output.",
+			"  * `-t`, `--type=<type>`:",
+			"    File type.",
+			"",
+		}
+
+		got, err := RonnToDocopt(lines).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::{ArgAction, ArgGroup, Parser};\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"copy\")]\n" +
+			"#[command(group(ArgGroup::new(\"group_1\").required(true).args([\"move\", \"link\"])))]\n" +
+			"pub struct Cli {\n" +
+			"    /// Move the file, \"safely\".\n" +
+			"    #[arg(long = \"move\")]\n" +
+			"    pub r#move: bool,\n" +
+			"\n" +
+			"    /// Link the file.\n" +
+			"    #[arg(long = \"link\")]\n" +
+			"    pub link: bool,\n" +
+			"\n" +
+			"    /// More output.\n" +
+			"    #[arg(short = 'v', action = ArgAction::Count)]\n" +
+			"    pub v: u8,\n" +
+			"\n" +
+			"    /// File type.\n" +
+			"    #[arg(short = 't', long = \"type\", value_name = \"TYPE\")]\n" +
+			"    pub r#type: Option<String>,\n" +
+			"\n" +
+			"    #[arg(long = \"list\")]\n" +
+			"    pub list: bool,\n" +
+			"\n" +
+			"    #[arg(value_name = \"SRC\")]\n" +
+			"    pub src: Option<String>,\n" +
+			"\n" +
+			"    #[arg(value_name = \"DST\")]\n" +
+			"    pub dst: Option<String>,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options have choices", func(t *testing.T) {
+		got, err := RonnToDocopt(choicesPage).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::Parser;\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"render\")]\n" +
+			"pub struct Cli {\n" +
+			"    /// Output format\n" +
+			"    #[arg(short = 'f', long = \"format\", value_name = \"FMT\", value_parser = [\"json\", \"yaml\", \"text\"], default_value = \"json\")]\n" +
+			"    pub format: String,\n" +
+			"\n" +
+			"    /// Compression level.\n" +
+			"    #[arg(long = \"level\", value_name = \"N\", value_parser = [\"1\", \"2\", \"3\"], default_value = \"2\")]\n" +
+			"    pub level: String,\n" +
+			"\n" +
+			"    #[arg(value_name = \"FILE\")]\n" +
+			"    pub file: String,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when an option value is written with a space", func(t *testing.T) {
+		got, err := RonnToDocopt(optionValuePage).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::{ArgAction, Parser};\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"tool\")]\n" +
+			"pub struct Cli {\n" +
+			"    /// Set a variable.\n" +
+			"    #[arg(short = 'D', value_name = \"VAR\", action = ArgAction::Append)]\n" +
+			"    pub d: Vec<String>,\n" +
+			"\n" +
+			"    #[arg(value_name = \"FILE\")]\n" +
+			"    pub file: String,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are bound to environment variables", func(t *testing.T) {
+		got, err := RonnToDocopt(envPage).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::Parser;\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"tool\")]\n" +
+			"pub struct Cli {\n" +
+			"    /// Speed in knots.\n" +
+			"    #[arg(long = \"speed\", value_name = \"KN\", default_value = \"10\", env = \"TOOL_SPEED\")]\n" +
+			"    pub speed: i64,\n" +
+			"\n" +
+			"    /// Name of the ship.\n" +
+			"    #[arg(long = \"name\", value_name = \"NAME\", env = \"TOOL_NAME\")]\n" +
+			"    pub name: Option<String>,\n" +
+			"\n" +
+			"    /// Colorize the output.\n" +
+			"    #[arg(long = \"color\", env = \"TOOL_COLOR\")]\n" +
+			"    pub color: bool,\n" +
+			"\n" +
+			"    #[arg(value_name = \"FILE\")]\n" +
+			"    pub file: String,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when options are deprecated or hidden", func(t *testing.T) {
+		got, err := RonnToDocopt(annotatedPage).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "// Code generated by ronn2docopt. DO NOT EDIT.\n" +
+			"\n" +
+			"use clap::Parser;\n" +
+			"\n" +
+			"#[derive(Parser, Debug)]\n" +
+			"#[command(name = \"thing\")]\n" +
+			"pub struct Cli {\n" +
+			"    /// Speed in knots.\n" +
+			"    #[arg(long = \"speed\", value_name = \"KN\")]\n" +
+			"    pub speed: Option<String>,\n" +
+			"\n" +
+			"    /// Speed in knots. (deprecated: use --speed)\n" +
+			"    #[arg(long = \"velocity\", value_name = \"KN\")]\n" +
+			"    pub velocity: Option<String>,\n" +
+			"\n" +
+			"    /// Dump internal state.\n" +
+			"    #[arg(long = \"debug\", hide = true)]\n" +
+			"    pub debug: bool,\n" +
+			"\n" +
+			"    /// Trace everything.\n" +
+			"    #[arg(long = \"trace\", hide = true)]\n" +
+			"    pub trace: bool,\n" +
+			"\n" +
+			"    #[arg(value_name = \"FILE\")]\n" +
+			"    pub file: String,\n" +
+			"}\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+}
+
+func TestClapFieldName(t *testing.T) {
+	tests := map[string]commandOption{
+		"speed":   {Name: "-s <kn> --speed=<kn>", Flags: []string{"-s", "--speed"}},
+		"v":       {Name: "-v", Flags: []string{"-v"}},
+		"verbose": {Name: "`--verbose"},
+	}
+
+	for want, o := range tests {
+		if got := clapFieldName(o); got != want {
+			t.Errorf("clapFieldName(%q) got = %q, want %q", o.Name, got, want)
+		}
+	}
+
+	t.Run("when an option declaration has no flags", func(t *testing.T) {
+		got, err := RonnToDocopt(noFlagsPage).Clap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(got, "verbose") || !strings.Contains(got, "pub quiet: bool") {
+			t.Errorf("clap got = %s, want only --quiet", got)
+		}
+	})
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := map[string]string{
+		"ship":    "Ship",
+		"dry-run": "DryRun",
+		"set_all": "SetAll",
+	}
+
+	for in, want := range tests {
+		if got := pascalCase(in); got != want {
+			t.Errorf("pascalCase(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
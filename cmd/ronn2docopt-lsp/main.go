@@ -0,0 +1,32 @@
+// ronn2docopt-lsp is a language server for ronn pages, that talks the language server protocol on stdin and stdout.
+//
+// With -config, pages are read with the section names of a config file, see ronn2docopt.ReadConfigFile.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ghostsquad/ronn2docopt"
+	"github.com/ghostsquad/ronn2docopt/lsp"
+)
+
+func main() {
+	configFile := flag.String("config", "", "file of the section names and headings of localized pages")
+	flag.Parse()
+
+	var config *ronn2docopt.Config
+	if *configFile != "" {
+		var err error
+		if config, err = ronn2docopt.ReadConfigFile(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, "ronn2docopt-lsp:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := lsp.NewServerWithConfig(os.Stdin, os.Stdout, config).Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, "ronn2docopt-lsp:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,19 @@
+// ronn2docopt-vet checks that docopt usage strings match the ronn pages they are generated from.
+//
+// Run it on its own, or with go vet:
+//
+//	ronn2docopt-vet ./...
+//	go vet -vettool=$(which ronn2docopt-vet) ./...
+//
+// With -fix, it regenerates the usage strings that differ.
+// With -config, the ronn pages are read with the section names of a config file, see ronn2docopt.ReadConfigFile.
+package main
+
+import (
+	"github.com/ghostsquad/ronn2docopt/usagecheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(usagecheck.Analyzer)
+}
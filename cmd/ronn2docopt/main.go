@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docopt/docopt-go"
+	"github.com/ghostsquad/ronn2docopt"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var version = "dev"
+
+const usage = `ronn2docopt - convert ronn man pages to docopt usage strings, roff and HTML man pages.
+
+Usage:
+  ronn2docopt [options] [--config=<file>] [--vars=<file>] [-D <var>]... <file>
+  ronn2docopt readme [--check] [--config=<file>] [--vars=<file>] [-D <var>]... <file> <markdown>
+  ronn2docopt lint [--json] [--max-option-length=<n>] [--rule=<setting>]... [--config=<file>] [--vars=<file>] [-D <var>]... <file>
+  ronn2docopt suite [--json | --command=<path>] [--config=<file>] [--vars=<file>] [-D <var>]... <page>...
+  ronn2docopt fmt [-d | -w] [--config=<file>] <page>...
+  ronn2docopt -h | --help
+  ronn2docopt --version
+
+Options:
+  -h --help              Show this screen.
+  --version              Show version.
+  --format=<name>        Output format, one of docopt, ansi, json, markdown, roff, html, argparse or clap. [default: docopt]
+  --template=<file>      Write the output of a text/template file, executed over the parsed page, instead of a format.
+  -r --roff              Write a roff man page instead of the docopt usage.
+  -5 --html              Write an HTML man page instead of the docopt usage.
+  --argparse             Write a Python argparse parser instead of the docopt usage.
+  --clap                 Write a Rust clap derive parser instead of the docopt usage.
+  --color                Style the docopt usage with ANSI escapes, for terminals.
+  -f --fragment          Write only the HTML man page content, for embedding in another page.
+  --style=<css>          CSS file to embed in the HTML man page, or "man" for the default style.
+  --date=<date>          Date shown in the man page footer. Defaults to the current month.
+  --manual=<manual>      Name of the manual shown in the man page header.
+  --organization=<name>  Organization shown in the man page footer.
+  --check                Only check that the markdown file is up to date, don't write it.
+  --json                 Write lint issues, or the suite usage and command map, as JSON.
+  --rule=<setting>       Set the severity of a lint rule, e.g. option-order=off or missing-default=error.
+  --max-option-length=<n>  Longest option name allowed by the option-length rule. [default: 30]
+  -D <var>               Set a template variable, e.g. -D Version=1.2.0.
+  --vars=<file>          Read template variables from a file of name=value lines.
+  --command=<path>       Write the usage of a subcommand of the suite, e.g. "ship" or "ship new".
+  --config=<file>        Read the section names and headings of localized pages from a file of name=value lines.
+  -d                     Display diffs instead of the formatted pages.
+  -w                     Write the formatted pages back to their files instead of stdout.
+
+The readme command replaces the content between the markers
+  <!-- ronn2docopt:begin -->
+  <!-- ronn2docopt:end -->
+of the markdown file with the usage and an options table.
+
+The lint command checks the page against these rules:
+  description-period  option descriptions end with a period
+  option-order        options are in alphabetical order within an option section
+  missing-default     options that take a value have a [default: ...]
+  default-syntax      defaults are written as [default: <value>]
+  option-length       option names are not too long
+  synopsis-arguments  documented arguments match the synopsis
+  default-value       defaults are valid choices, and only options that take a value have one
+  repeatable-option   options marked [repeatable] are used in the synopsis
+  hidden-option       options marked [hidden] are used in the synopsis
+  indentation         option bullets and bodies are indented consistently
+Severities are off, info, warning and error. Lint fails when it finds errors.
+
+Pages can include other pages, e.g. shared options, with
+  <!-- include: common-options.ronn -->
+Paths are relative to the including page.
+
+Template variables, e.g. {{.Version}}, are replaced before the page is converted.
+Their values come from -D, then the --vars file, then the environment, e.g. RONN_VERSION.
+Undefined variables are errors.
+
+The suite command merges the pages of a git style command suite into one usage.
+naval_fate-ship.1.ronn documents the ship command of naval_fate.1.ronn,
+other pages are subcommands of the page referenced in their SEE ALSO section.
+
+The fmt command rewrites the SYNOPSIS and OPTIONS sections into canonical form.
+
+The --config file maps the sections to read, and the headings to write, e.g.
+  synopsis=SYNOPSIS, SYNTAXE
+  options=OPTIONS, OPTIONEN
+  options-heading=Optionen (options):`
+
+func main() {
+	arguments, _ := docopt.Parse(usage, nil, true, version, false)
+
+	output, err := run(arguments)
+	fmt.Print(output)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ronn2docopt:", err)
+		os.Exit(1)
+	}
+}
+
+func run(arguments map[string]interface{}) (string, error) {
+	config, err := readConfig(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	if arguments["fmt"].(bool) {
+		return runFmt(arguments, config)
+	}
+
+	if arguments["suite"].(bool) {
+		return runSuite(arguments, config)
+	}
+
+	file := arguments["<file>"].(string)
+
+	lines, sources, err := ronn2docopt.ReadRonnFileWithIncludes(file)
+	if err != nil {
+		return "", err
+	}
+
+	lines, err = substituteVars(arguments, lines, sources)
+	if err != nil {
+		return "", err
+	}
+
+	if arguments["readme"].(bool) {
+		return runReadme(arguments, lines, config)
+	}
+
+	if arguments["lint"].(bool) {
+		return runLint(arguments, lines, sources, config)
+	}
+
+	format := stringArgument(arguments, "--format")
+
+	// the format flags are shortcuts for --format
+	switch {
+	case arguments["--roff"].(bool):
+		format = "roff"
+	case arguments["--html"].(bool) || arguments["--fragment"].(bool):
+		format = "html"
+	case arguments["--argparse"].(bool):
+		format = "argparse"
+	case arguments["--clap"].(bool):
+		format = "clap"
+	case arguments["--color"].(bool):
+		format = "ansi"
+	}
+
+	var renderer ronn2docopt.Renderer
+
+	if templateFile := stringArgument(arguments, "--template"); templateFile != "" {
+		renderer, err = ronn2docopt.ReadTemplateFile(templateFile)
+	} else {
+		renderer, err = ronn2docopt.FindRenderer(format)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	options, err := renderOptions(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	page := ronn2docopt.ParsePage(lines, config)
+	for _, diagnostic := range page.DocOpt.Diagnostics {
+		fmt.Fprintln(os.Stderr, diagnostic.Locate(sources))
+	}
+
+	output, err := renderer.Render(page, options)
+
+	return string(output), err
+}
+
+// The roff and HTML man page options, other renderers ignore them
+func renderOptions(arguments map[string]interface{}) (ronn2docopt.RenderOptions, error) {
+	options := ronn2docopt.RenderOptions{
+		Roff: ronn2docopt.RoffOptions{
+			Date:         stringArgument(arguments, "--date"),
+			Manual:       stringArgument(arguments, "--manual"),
+			Organization: stringArgument(arguments, "--organization"),
+		},
+		HTML: ronn2docopt.HTMLOptions{
+			Fragment: arguments["--fragment"].(bool),
+		},
+	}
+
+	if options.Roff.Date == "" {
+		options.Roff.Date = time.Now().Format("January 2006")
+	}
+
+	switch style := stringArgument(arguments, "--style"); style {
+	case "":
+	case "man":
+		options.HTML.Stylesheet = ronn2docopt.DefaultStylesheet
+	default:
+		css, err := ioutil.ReadFile(style)
+		if err != nil {
+			return options, err
+		}
+
+		options.HTML.Stylesheet = string(css)
+	}
+
+	return options, nil
+}
+
+func runReadme(arguments map[string]interface{}, lines []string, config *ronn2docopt.Config) (string, error) {
+	markdownFile := arguments["<markdown>"].(string)
+	check := arguments["--check"].(bool)
+
+	markdown := ronn2docopt.RonnToDocoptWithConfig(lines, config).Markdown()
+
+	changed, err := ronn2docopt.UpdateMarkdownFile(markdownFile, markdown, check)
+	if err != nil {
+		return "", err
+	}
+
+	if changed && check {
+		return "", fmt.Errorf("%s is out of date, run: ronn2docopt readme %s %s",
+			markdownFile, arguments["<file>"], markdownFile)
+	}
+
+	return "", nil
+}
+
+func runLint(arguments map[string]interface{}, lines []string, sources ronn2docopt.SourceMap, sections *ronn2docopt.Config) (string, error) {
+	file := arguments["<file>"].(string)
+
+	config := ronn2docopt.LintConfig{Sections: sections}
+	for _, setting := range arguments["--rule"].([]string) {
+		if err := config.ParseSetting(setting); err != nil {
+			return "", err
+		}
+	}
+
+	maxOptionLength, err := strconv.Atoi(stringArgument(arguments, "--max-option-length"))
+	if err != nil {
+		return "", fmt.Errorf("--max-option-length: %s", err)
+	}
+
+	var rules []ronn2docopt.LintRule
+	for _, r := range ronn2docopt.DefaultLintRules {
+		if _, ok := r.(*ronn2docopt.OptionLengthRule); ok {
+			r = &ronn2docopt.OptionLengthRule{Max: maxOptionLength}
+		}
+		rules = append(rules, r)
+	}
+
+	var issues []ronn2docopt.LintIssue
+	for _, issue := range ronn2docopt.Lint(lines, rules, &config) {
+		issues = append(issues, issue.Locate(sources))
+	}
+
+	var buffer bytes.Buffer
+	if arguments["--json"].(bool) {
+		if issues == nil {
+			issues = []ronn2docopt.LintIssue{}
+		}
+
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(issues); err != nil {
+			return "", err
+		}
+	} else {
+		for _, issue := range issues {
+			buffer.WriteString(issue.String() + "\n")
+		}
+	}
+
+	errors := 0
+	for _, issue := range issues {
+		if issue.Severity == ronn2docopt.SeverityError {
+			errors++
+		}
+	}
+
+	if errors > 0 {
+		return buffer.String(), fmt.Errorf("%s: %d lint error(s)", file, errors)
+	}
+
+	return buffer.String(), nil
+}
+
+func runSuite(arguments map[string]interface{}, config *ronn2docopt.Config) (string, error) {
+	var pages []ronn2docopt.Page
+
+	for _, page := range arguments["<page>"].([]string) {
+		lines, sources, err := ronn2docopt.ReadRonnFileWithIncludes(page)
+		if err != nil {
+			return "", err
+		}
+
+		lines, err = substituteVars(arguments, lines, sources)
+		if err != nil {
+			return "", err
+		}
+
+		pages = append(pages, ronn2docopt.Page{Name: ronn2docopt.PageName(page, lines), Lines: lines})
+	}
+
+	suite, err := ronn2docopt.NewSuite(pages, config)
+	if err != nil {
+		return "", err
+	}
+
+	if path := stringArgument(arguments, "--command"); path != "" {
+		c := suite.Command(path)
+		if c == nil {
+			return "", fmt.Errorf("%s has no %s command", suite.Program, path)
+		}
+
+		return c.Usage.String() + "\n", nil
+	}
+
+	if arguments["--json"].(bool) {
+		var buffer bytes.Buffer
+
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(map[string]interface{}{
+			"program":  suite.Program,
+			"usage":    suite.Usage.String(),
+			"commands": suite.CommandMap(),
+		})
+
+		return buffer.String(), err
+	}
+
+	return suite.Usage.String() + "\n", nil
+}
+
+func runFmt(arguments map[string]interface{}, config *ronn2docopt.Config) (string, error) {
+	var buffer bytes.Buffer
+
+	for _, page := range arguments["<page>"].([]string) {
+		lines, err := ronn2docopt.ReadRonnFile(page)
+		if err != nil {
+			return buffer.String(), err
+		}
+
+		original := strings.Join(lines, "\n") + "\n"
+		formatted := strings.Join(ronn2docopt.FormatRonnWithConfig(lines, config), "\n") + "\n"
+
+		switch {
+		case arguments["-d"].(bool):
+			if formatted == original {
+				continue
+			}
+
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(original),
+				B:        difflib.SplitLines(formatted),
+				FromFile: page + ".orig",
+				ToFile:   page,
+				Context:  3,
+			})
+			if err != nil {
+				return buffer.String(), err
+			}
+
+			buffer.WriteString(diff)
+		case arguments["-w"].(bool):
+			if formatted == original {
+				continue
+			}
+
+			info, err := os.Stat(page)
+			if err != nil {
+				return buffer.String(), err
+			}
+
+			if err := ioutil.WriteFile(page, []byte(formatted), info.Mode()); err != nil {
+				return buffer.String(), err
+			}
+		default:
+			buffer.WriteString(formatted)
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+// Replaces the template variables with the values of -D, the --vars file and the environment, in that order
+func substituteVars(arguments map[string]interface{}, lines []string, sources ronn2docopt.SourceMap) ([]string, error) {
+	vars := ronn2docopt.Vars{}
+
+	if varsFile := stringArgument(arguments, "--vars"); varsFile != "" {
+		fileVars, err := ronn2docopt.ReadVarsFile(varsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		vars = fileVars
+	}
+
+	for _, setting := range arguments["-D"].([]string) {
+		if err := vars.ParseSetting(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	lines, undefined := ronn2docopt.SubstituteVars(lines, vars, os.LookupEnv)
+	if len(undefined) == 0 {
+		return lines, nil
+	}
+
+	var messages []string
+	for _, diagnostic := range undefined {
+		messages = append(messages, diagnostic.Locate(sources).String())
+	}
+
+	return nil, fmt.Errorf("%d undefined variable(s), set them with -D <name>=<value>:\n%s",
+		len(undefined), strings.Join(messages, "\n"))
+}
+
+// The section names and headings of the --config file, or nil for the default config
+func readConfig(arguments map[string]interface{}) (*ronn2docopt.Config, error) {
+	configFile := stringArgument(arguments, "--config")
+	if configFile == "" {
+		return nil, nil
+	}
+
+	return ronn2docopt.ReadConfigFile(configFile)
+}
+
+// Returns the value of an option that takes an argument, or "" when it's not given
+func stringArgument(arguments map[string]interface{}, name string) string {
+	if s, ok := arguments[name].(string); ok {
+		return s
+	}
+
+	return ""
+}
@@ -0,0 +1,395 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A commandNode is a command of the synopsis, with the arguments and options of the usage lines that run it.
+// Code generators build their parsers from this tree, rather than from the synopsis directly.
+type commandNode struct {
+	Name      string
+	Path      []string
+	Desc      string
+	Arguments []commandArgument
+	Options   []commandOption
+	Groups    []commandGroup
+	Commands  []*commandNode
+
+	// a usage line runs this command without a subcommand
+	Runnable bool
+
+	usages int
+}
+
+type commandArgument struct {
+	Name     string
+	Desc     string
+	Optional bool
+	Repeated bool
+
+	uses int
+}
+
+type commandOption struct {
+	Name     string
+	Flags    []string
+	Value    string
+	Desc     string
+	Default  Default
+	Choices  []string
+	Repeated bool
+
+	// the environment variable that overrides the option
+	Env string
+
+	Deprecated  bool
+	Deprecation string
+	Hidden      bool
+
+	// index in Groups, or -1
+	Group int
+}
+
+// Options of a mutually exclusive group, e.g. [--moored|--drifting]
+type commandGroup struct {
+	Required bool
+	Options  []int
+}
+
+// Builds the command tree from the synopsis.
+// Commands anywhere at the top level of a usage line are part of its command path,
+// e.g. "ship <name> move <x> <y>" runs "ship move" with the arguments <name> <x> <y>,
+// and alternatives of commands, e.g. "mine (set|remove) <x> <y>", are separate commands.
+func (d *DocOpt) commandTree() (*commandNode, error) {
+	usages, err := ParseSynopsis(d.Synopsis)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(usages) == 0 {
+		return nil, fmt.Errorf("synopsis is empty")
+	}
+
+	root := &commandNode{Name: usages[0].Program}
+
+	for _, u := range usages {
+		children := []*Pattern{u.Pattern}
+		if u.Pattern.Kind == SequencePattern {
+			children = u.Pattern.Children
+		}
+
+		for _, b := range expandCommands(children, nil, nil) {
+			node := root
+			for _, c := range b.commands {
+				node = node.command(c, d.Commands)
+			}
+
+			node.usages++
+			node.Runnable = true
+
+			seen := map[string]bool{}
+			node.collectSequence(d, b.rest, false, seen)
+
+			for i := range node.Arguments {
+				if seen[node.Arguments[i].Name] {
+					node.Arguments[i].uses++
+				}
+			}
+		}
+	}
+
+	root.finish()
+
+	return root, nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// A usage line split into its command path, and the rest of its patterns
+type commandBranch struct {
+	commands []string
+	rest     []*Pattern
+}
+
+func expandCommands(children []*Pattern, commands []string, rest []*Pattern) []commandBranch {
+	if len(children) == 0 {
+		return []commandBranch{{commands: commands, rest: rest}}
+	}
+
+	p := children[0]
+
+	if p.Kind == CommandPattern {
+		return expandCommands(children[1:], append(commands[:len(commands):len(commands)], p.Name), rest)
+	}
+
+	if alternatives := commandAlternatives(p); alternatives != nil {
+		var branches []commandBranch
+		for _, c := range alternatives {
+			branches = append(branches, expandCommands(children[1:], append(commands[:len(commands):len(commands)], c), rest)...)
+		}
+
+		return branches
+	}
+
+	return expandCommands(children[1:], commands, append(rest[:len(rest):len(rest)], p))
+}
+
+// e.g. (set|remove) returns [set remove]
+func commandAlternatives(p *Pattern) []string {
+	if p.Kind != RequiredPattern || len(p.Children) != 1 || p.Children[0].Kind != EitherPattern {
+		return nil
+	}
+
+	var names []string
+	for _, branch := range p.Children[0].Children {
+		leaf := singleLeaf(branch)
+		if leaf == nil || leaf.Kind != CommandPattern {
+			return nil
+		}
+
+		names = append(names, leaf.Name)
+	}
+
+	return names
+}
+
+// The leaf of a sequence with a single child, or the pattern itself when it's a leaf
+func singleLeaf(p *Pattern) *Pattern {
+	for p.Kind == SequencePattern && len(p.Children) == 1 {
+		p = p.Children[0]
+	}
+
+	if len(p.Children) > 0 || p.Kind == SequencePattern {
+		return nil
+	}
+
+	return p
+}
+
+func (n *commandNode) command(name string, commands []HelpCommand) *commandNode {
+	for _, c := range n.Commands {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	c := &commandNode{Name: name, Path: append(n.Path[:len(n.Path):len(n.Path)], name)}
+	for _, h := range commands {
+		if h.Name == name {
+			c.Desc = h.Desc
+		}
+	}
+
+	n.Commands = append(n.Commands, c)
+
+	return c
+}
+
+func (n *commandNode) collect(d *DocOpt, p *Pattern, optional bool, seen map[string]bool) {
+	switch p.Kind {
+	case ArgumentPattern:
+		n.argument(d, p, optional)
+		seen[p.Name] = true
+	case OptionPattern:
+		n.option(d, p)
+	case OptionsShortcutPattern:
+		for _, s := range d.HelpOptionSections {
+			for _, o := range s.Options {
+				// a malformed declaration, e.g. an unclosed backtick, may have no flags
+				if flags := optionFlags(o.Name); len(flags) > 0 {
+					n.option(d, &Pattern{Kind: OptionPattern, Name: flags[0]})
+				}
+			}
+		}
+	case SequencePattern:
+		n.collectSequence(d, p.Children, optional, seen)
+	case RequiredPattern, OptionalPattern:
+		optional = optional || p.Kind == OptionalPattern
+
+		if len(p.Children) == 1 && p.Children[0].Kind == EitherPattern && n.group(d, p.Children[0], !optional) {
+			return
+		}
+
+		for _, c := range p.Children {
+			n.collect(d, c, optional, seen)
+		}
+	case EitherPattern:
+		if n.group(d, p, false) {
+			return
+		}
+
+		for _, c := range p.Children {
+			n.collect(d, c, true, seen)
+		}
+	}
+}
+
+// Collects the patterns of a sequence, where the value of an option may follow it, e.g. [-D <var>]
+func (n *commandNode) collectSequence(d *DocOpt, children []*Pattern, optional bool, seen map[string]bool) {
+	for i := 0; i < len(children); i++ {
+		c := children[i]
+
+		if i+1 < len(children) && d.isOptionValue(c, children[i+1]) {
+			o := n.option(d, c)
+			n.Options[o].Repeated = n.Options[o].Repeated || children[i+1].Repeated
+			i++
+			continue
+		}
+
+		n.collect(d, c, optional, seen)
+	}
+}
+
+// Adds the alternatives as a mutually exclusive group, when they are all single options
+func (n *commandNode) group(d *DocOpt, either *Pattern, required bool) bool {
+	var leaves []*Pattern
+	for _, branch := range either.Children {
+		leaf := singleLeaf(branch)
+		if leaf == nil || leaf.Kind != OptionPattern {
+			return false
+		}
+
+		leaves = append(leaves, leaf)
+	}
+
+	var options []int
+	for _, leaf := range leaves {
+		i := n.option(d, leaf)
+		if !containsInt(options, i) {
+			options = append(options, i)
+		}
+	}
+
+	// e.g. -h | --help are the same option
+	if len(options) < 2 {
+		return true
+	}
+
+	for _, i := range options {
+		if n.Options[i].Group != -1 {
+			return true
+		}
+	}
+
+	for _, i := range options {
+		n.Options[i].Group = len(n.Groups)
+	}
+
+	n.Groups = append(n.Groups, commandGroup{Required: required, Options: options})
+
+	return true
+}
+
+func (n *commandNode) argument(d *DocOpt, p *Pattern, optional bool) {
+	for i := range n.Arguments {
+		a := &n.Arguments[i]
+		if a.Name == p.Name {
+			a.Optional = a.Optional || optional
+			a.Repeated = a.Repeated || p.Repeated
+			return
+		}
+	}
+
+	a := commandArgument{Name: p.Name, Optional: optional, Repeated: p.Repeated}
+	for _, h := range d.Arguments {
+		if h.Name == p.Name {
+			a.Desc = h.Desc
+		}
+	}
+
+	n.Arguments = append(n.Arguments, a)
+}
+
+// Adds the documented option with the flag of the leaf, and returns its index
+func (n *commandNode) option(d *DocOpt, leaf *Pattern) int {
+	name := leaf.Name
+
+	help := d.documentedOption(optionFlags(leaf.Name)[0])
+	if help != nil {
+		name = help.Name
+	}
+
+	for i := range n.Options {
+		if n.Options[i].Name == name {
+			n.Options[i].Repeated = n.Options[i].Repeated || leaf.Repeated
+			return i
+		}
+	}
+
+	o := commandOption{
+		Name:     name,
+		Flags:    optionFlags(name),
+		Value:    optionValue(name),
+		Repeated: leaf.Repeated,
+		Group:    -1,
+	}
+
+	if help != nil {
+		o.Repeated = o.Repeated || help.Cardinality != SingleOption
+		o.Desc = help.Desc
+		o.Default = help.Default
+		o.Choices = help.Choices
+		o.Env = help.Env
+		o.Deprecated = help.Deprecated
+		o.Deprecation = help.Deprecation
+		o.Hidden = help.Hidden
+	}
+
+	n.Options = append(n.Options, o)
+
+	return len(n.Options) - 1
+}
+
+// Returns whether an option of the command, or of one of its subcommands, is bound to an environment variable
+func (n *commandNode) hasEnv() bool {
+	for _, o := range n.Options {
+		if o.Env != "" {
+			return true
+		}
+	}
+
+	for _, c := range n.Commands {
+		if c.hasEnv() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Arguments not used by every usage line of the command are optional
+func (n *commandNode) finish() {
+	for i := range n.Arguments {
+		if n.Arguments[i].uses < n.usages {
+			n.Arguments[i].Optional = true
+		}
+	}
+
+	for _, c := range n.Commands {
+		c.finish()
+	}
+}
+
+// The argument placeholder of an option, e.g. "-s <kn> --speed=<kn>" returns <kn>
+func optionValue(name string) string {
+	for _, f := range splitOptionName(name) {
+		if i := strings.IndexAny(f, "= "); i != -1 {
+			return strings.TrimSpace(f[i+1:])
+		}
+	}
+
+	return ""
+}
+
+func containsInt(ints []int, i int) bool {
+	for _, n := range ints {
+		if n == i {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,165 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config maps the ronn sections to read, and the docopt headings to write.
+// Section names are matched case-insensitively, the first alias found wins,
+// so localized pages can list e.g. "SYNTAXE" or "OPTIONEN" alongside the defaults.
+type Config struct {
+	SynopsisSections    []string
+	ArgumentsSections   []string
+	CommandsSections    []string
+	OptionsSections     []string
+	EnvironmentSections []string
+
+	// docopt itself looks for "usage:" and "options:" (case-insensitive),
+	// keep those words in the headings if the output is parsed by docopt
+	UsageHeading     string
+	ArgumentsHeading string
+	CommandsHeading  string
+	OptionsHeading   string
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		SynopsisSections:    []string{"SYNOPSIS"},
+		ArgumentsSections:   []string{"ARGUMENTS", "POSITIONAL ARGUMENTS"},
+		CommandsSections:    []string{"COMMANDS"},
+		OptionsSections:     []string{"OPTIONS"},
+		EnvironmentSections: []string{"ENVIRONMENT"},
+
+		UsageHeading:     "Usage:",
+		ArgumentsHeading: "Arguments:",
+		CommandsHeading:  "Commands:",
+		OptionsHeading:   "Options:",
+	}
+}
+
+// ReadConfigFile reads a config from name=value settings, one per line, see ParseSetting.
+// Blank lines and lines starting with # are ignored, and settings that aren't given fall back to the default config.
+func ReadConfigFile(configFile string) (*Config, error) {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := ReadLines(NewLineScanner(file, DefaultMaxLineLength))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", configFile, err)
+	}
+
+	config := &Config{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := config.ParseSetting(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", configFile, i+1, err)
+		}
+	}
+
+	return config, nil
+}
+
+// ParseSetting applies a name=value setting. The sections to read are comma separated, e.g.
+//
+//	options=OPTIONS, OPTIONEN
+//
+// and the headings to write are taken as is, e.g. options-heading=Optionen (options):
+// Section names are synopsis, arguments, commands, options and environment,
+// headings are usage-heading, arguments-heading, commands-heading and options-heading.
+func (c *Config) ParseSetting(setting string) error {
+	parts := strings.SplitN(setting, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid config setting %q, want <name>=<value>", setting)
+	}
+
+	name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	var sections []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			sections = append(sections, s)
+		}
+	}
+
+	switch name {
+	case "synopsis":
+		c.SynopsisSections = sections
+	case "arguments":
+		c.ArgumentsSections = sections
+	case "commands":
+		c.CommandsSections = sections
+	case "options":
+		c.OptionsSections = sections
+	case "environment":
+		c.EnvironmentSections = sections
+	case "usage-heading":
+		c.UsageHeading = value
+	case "arguments-heading":
+		c.ArgumentsHeading = value
+	case "commands-heading":
+		c.CommandsHeading = value
+	case "options-heading":
+		c.OptionsHeading = value
+	default:
+		return fmt.Errorf("unknown config setting %q", name)
+	}
+
+	return nil
+}
+
+// Returns the config, filling any empty field from the default config
+func (c *Config) withDefaults() *Config {
+	d := DefaultConfig()
+	if c == nil {
+		return d
+	}
+
+	merged := *c
+
+	if len(merged.SynopsisSections) == 0 {
+		merged.SynopsisSections = d.SynopsisSections
+	}
+
+	if len(merged.ArgumentsSections) == 0 {
+		merged.ArgumentsSections = d.ArgumentsSections
+	}
+
+	if len(merged.CommandsSections) == 0 {
+		merged.CommandsSections = d.CommandsSections
+	}
+
+	if len(merged.OptionsSections) == 0 {
+		merged.OptionsSections = d.OptionsSections
+	}
+
+	if len(merged.EnvironmentSections) == 0 {
+		merged.EnvironmentSections = d.EnvironmentSections
+	}
+
+	if merged.UsageHeading == "" {
+		merged.UsageHeading = d.UsageHeading
+	}
+
+	if merged.ArgumentsHeading == "" {
+		merged.ArgumentsHeading = d.ArgumentsHeading
+	}
+
+	if merged.CommandsHeading == "" {
+		merged.CommandsHeading = d.CommandsHeading
+	}
+
+	if merged.OptionsHeading == "" {
+		merged.OptionsHeading = d.OptionsHeading
+	}
+
+	return &merged
+}
@@ -0,0 +1,153 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var localizedFile = []string{
+	"naval_fate(1) -- Schiffe versenken",
+	"",
+	"## SYNTAXE",
+	"",
+	"`naval_fate` `ship new <name>...`<br>",
+	"`naval_fate` `--version`<br>",
+	"",
+	"## OPTIONEN",
+	"",
+	"  * `--version`:",
+	"    Version anzeigen.",
+	"",
+}
+
+func TestRonnToDocoptWithConfig(t *testing.T) {
+	t.Run("when sections and headings are localized", func(t *testing.T) {
+		config := &Config{
+			SynopsisSections: []string{"SYNOPSIS", "SYNTAXE"},
+			OptionsSections:  []string{"OPTIONS", "OPTIONEN"},
+			UsageHeading:     "Usage (Verwendung):",
+			OptionsHeading:   "Optionen (options):",
+		}
+
+		d := RonnToDocoptWithConfig(localizedFile, config)
+		got := d.String()
+
+		want := "Usage (Verwendung):\n" +
+			"  naval_fate ship new <name>...\n" +
+			"  naval_fate --version\n" +
+			"\n" +
+			"Optionen (options):\n" +
+			"  --version  Version anzeigen."
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when section names differ in case", func(t *testing.T) {
+		lines := []string{
+			"## Synopsis",
+			"`naval_fate` `--version`",
+			"## Options",
+			"  * `--version`:",
+			"    Show version.",
+		}
+
+		d := RonnToDocopt(lines)
+
+		got := len(d.HelpOptionSections)
+		want := 1
+		if got != want {
+			t.Errorf("number of help option sections got = %d, want %d", got, want)
+		}
+
+		if d.Synopsis != "  naval_fate --version" {
+			t.Errorf("synopsis got = %s, want naval_fate --version", d.Synopsis)
+		}
+	})
+
+	t.Run("when config is nil", func(t *testing.T) {
+		d := RonnToDocoptWithConfig(exampleFile, nil)
+
+		got := len(d.HelpOptionSections)
+		want := 2
+		if got != want {
+			t.Errorf("number of help option sections got = %d, want %d", got, want)
+		}
+	})
+}
+
+func TestConfig_WithDefaults(t *testing.T) {
+	c := &Config{OptionsHeading: "Optionen:"}
+	got := c.withDefaults()
+
+	if got.OptionsHeading != "Optionen:" {
+		t.Errorf("OptionsHeading got = %s, want Optionen:", got.OptionsHeading)
+	}
+
+	if got.UsageHeading != "Usage:" {
+		t.Errorf("UsageHeading got = %s, want Usage:", got.UsageHeading)
+	}
+
+	if len(got.SynopsisSections) != 1 || got.SynopsisSections[0] != "SYNOPSIS" {
+		t.Errorf("SynopsisSections got = %v, want [SYNOPSIS]", got.SynopsisSections)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := writeRonnFiles(t, map[string]string{
+		"sections.conf": "# german pages\nsynopsis=SYNOPSIS, SYNTAXE\noptions=OPTIONS, OPTIONEN\n\noptions-heading=Optionen (options):\n",
+		"invalid.conf":  "synopsis=SYNTAXE\nsection=OPTIONEN\n",
+	})
+	defer os.RemoveAll(dir)
+
+	t.Run("when a localized page is converted with the config", func(t *testing.T) {
+		config, err := ReadConfigFile(filepath.Join(dir, "sections.conf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := RonnToDocoptWithConfig(localizedFile, config).String()
+
+		want := "Usage:\n" +
+			"  naval_fate ship new <name>...\n" +
+			"  naval_fate --version\n" +
+			"\n" +
+			"Optionen (options):\n" +
+			"  --version  Version anzeigen."
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when a setting is unknown", func(t *testing.T) {
+		_, err := ReadConfigFile(filepath.Join(dir, "invalid.conf"))
+
+		want := "invalid.conf:2: unknown config setting \"section\""
+		if err == nil || !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("error got = %v, want suffix %s", err, want)
+		}
+	})
+}
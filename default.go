@@ -0,0 +1,189 @@
+package ronn2docopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DefaultKind int
+
+const (
+	NoDefault DefaultKind = iota
+	StringDefault
+	IntDefault
+	FloatDefault
+	DurationDefault
+	BoolDefault
+	ListDefault
+)
+
+var defaultKindNames = []string{"none", "string", "int", "float", "duration", "bool", "list"}
+
+var intDefaultRe = regexp.MustCompile(`^[+-]?[0-9]+$`)
+var floatDefaultRe = regexp.MustCompile(`^[+-]?([0-9]+\.[0-9]*|\.[0-9]+|[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+func (k DefaultKind) String() string {
+	return defaultKindNames[k]
+}
+
+func (k DefaultKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// A Default is the typed value of an option's [default: ...].
+// Text is the value as it's written, e.g. 1m30s, and Value is one of
+// string, int64, float64, time.Duration, bool or []string, depending on Kind.
+type Default struct {
+	Kind  DefaultKind `json:"kind"`
+	Text  string      `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+// Options without a default are null, and durations are written as their text, e.g. "1m30s"
+func (v Default) MarshalJSON() ([]byte, error) {
+	switch v.Kind {
+	case NoDefault:
+		return []byte("null"), nil
+	case DurationDefault:
+		return json.Marshal(map[string]interface{}{"kind": v.Kind, "text": v.Text, "value": v.Text})
+	}
+
+	type plain Default
+	return json.Marshal(plain(v))
+}
+
+// ParseDefault types the value of a default, e.g. "[default: 10]", or only its value, "10".
+// Values are tried as an int, a float, a duration (e.g. 30s), a bool (true or false),
+// and a comma separated list (e.g. json, yaml), and are strings otherwise.
+func ParseDefault(text string) (Default, error) {
+	s := defaultValueText(text)
+
+	if s == "" {
+		return Default{}, fmt.Errorf("default is empty")
+	}
+
+	if strings.ContainsAny(s, "[]") {
+		return Default{}, fmt.Errorf("malformed default %q, brackets are not allowed in the value", s)
+	}
+
+	if intDefaultRe.MatchString(s) {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return Default{Kind: IntDefault, Text: s, Value: i}, nil
+		}
+	}
+
+	if floatDefaultRe.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return Default{Kind: FloatDefault, Text: s, Value: f}, nil
+		}
+	}
+
+	if duration, err := time.ParseDuration(s); err == nil {
+		return Default{Kind: DurationDefault, Text: s, Value: duration}, nil
+	}
+
+	switch strings.ToLower(s) {
+	case "true":
+		return Default{Kind: BoolDefault, Text: s, Value: true}, nil
+	case "false":
+		return Default{Kind: BoolDefault, Text: s, Value: false}, nil
+	}
+
+	if strings.Contains(s, ",") {
+		var list []string
+		for _, item := range strings.Split(s, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+
+		return Default{Kind: ListDefault, Text: s, Value: list}, nil
+	}
+
+	return Default{Kind: StringDefault, Text: s, Value: s}, nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// Types the defaults of the options, once the option sections are complete.
+// Malformed defaults, defaults that are not one of the choices,
+// and defaults or choices of options that take no argument, are reported.
+func (d *DocOpt) typeDefaults() []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for i := range d.HelpOptionSections {
+		for j := range d.HelpOptionSections[i].Options {
+			o := &d.HelpOptionSections[i].Options[j]
+
+			if len(o.Choices) > 0 && !optionTakesValue(o.Name) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:    o.Line,
+					Code:    "default-value",
+					Message: fmt.Sprintf("option %s has choices, but takes no argument", o.Name),
+				})
+			}
+
+			if o.DefaultValue == "" {
+				continue
+			}
+
+			value, err := ParseDefault(o.DefaultValue)
+			if err != nil {
+				// brackets are already reported by the default-syntax lint rule
+				code := "default-value"
+				if strings.ContainsAny(defaultValueText(o.DefaultValue), "[]") {
+					code = "default-syntax"
+				}
+
+				diagnostics = append(diagnostics, Diagnostic{Line: o.Line, Code: code, Message: fmt.Sprintf("option %s: %s", o.Name, err)})
+				continue
+			}
+
+			// flags keep no default, generators would otherwise give them one
+			if !optionTakesValue(o.Name) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:    o.Line,
+					Code:    "default-value",
+					Message: fmt.Sprintf("option %s has a default, but takes no argument", o.Name),
+				})
+				continue
+			}
+
+			o.Default = value
+
+			if c := notAChoice(value, o.Choices); c != "" {
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:    o.Line,
+					Code:    "default-value",
+					Message: fmt.Sprintf("option %s: default %s is not one of the choices %s", o.Name, c, strings.Join(o.Choices, ", ")),
+				})
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// Returns the value of the default that is not one of the choices, if any. Every item of a list must be a choice.
+func notAChoice(value Default, choices []string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+
+	values := []string{value.Text}
+	if value.Kind == ListDefault {
+		values = value.Value.([]string)
+	}
+
+	for _, v := range values {
+		if !containsString(choices, v) {
+			return v
+		}
+	}
+
+	return ""
+}
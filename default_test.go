@@ -0,0 +1,121 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDefault(t *testing.T) {
+	tests := map[string]Default{
+		"[default: 10]":         {Kind: IntDefault, Text: "10", Value: int64(10)},
+		"-3":                    {Kind: IntDefault, Text: "-3", Value: int64(-3)},
+		"[default: 0.5]":        {Kind: FloatDefault, Text: "0.5", Value: 0.5},
+		"[default: 1e3]":        {Kind: FloatDefault, Text: "1e3", Value: 1000.0},
+		"[default: 1m30s]":      {Kind: DurationDefault, Text: "1m30s", Value: 90 * time.Second},
+		"[default: false]":      {Kind: BoolDefault, Text: "false", Value: false},
+		"[default: True]":       {Kind: BoolDefault, Text: "True", Value: true},
+		"[default: json, yaml]": {Kind: ListDefault, Text: "json, yaml", Value: []string{"json", "yaml"}},
+		"[default: ./here]":     {Kind: StringDefault, Text: "./here", Value: "./here"},
+		"[default: inf]":        {Kind: StringDefault, Text: "inf", Value: "inf"},
+	}
+
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			got, err := ParseDefault(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got = %#v, want %#v", got, want)
+			}
+		})
+	}
+
+	t.Run("when malformed", func(t *testing.T) {
+		errors := map[string]string{
+			"[default: [10]": `malformed default "[10", brackets are not allowed in the value`,
+			"[default: ]":    "default is empty",
+		}
+
+		for in, want := range errors {
+			_, err := ParseDefault(in)
+			if err == nil || err.Error() != want {
+				t.Errorf("ParseDefault(%q) err got = %v, want %s", in, err, want)
+			}
+		}
+	})
+}
+
+func TestRonnToDocopt_Defaults(t *testing.T) {
+	lines := []string{
+		"## OPTIONS",
+		"  * `--speed=<kn>`:",
+		"    Speed in knots. [default: [10]",
+		"  * `--moored`:",
+		"    Moored mine. [default: true]",
+		"  * `--timeout=<duration>`:",
+		"    Time to wait. [default: 30s]",
+	}
+
+	d := RonnToDocopt(lines)
+
+	var diagnostics []string
+	for _, diagnostic := range d.Diagnostics {
+		diagnostics = append(diagnostics, diagnostic.Code+" "+diagnostic.String())
+	}
+
+	want := []string{
+		`default-syntax 2: option --speed=<kn>: malformed default "[10", brackets are not allowed in the value`,
+		"default-value 4: option --moored has a default, but takes no argument",
+	}
+
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("diagnostics got = %q, want %q", diagnostics, want)
+	}
+
+	options := d.HelpOptionSections[0].Options
+
+	// malformed defaults are kept for rendering, but not typed
+	if got := fmt.Sprintf("%s %s", options[0].DefaultValue, options[0].Default.Kind); got != "[default: [10] none" {
+		t.Errorf("--speed default got = %s", got)
+	}
+
+	// flags don't get a default
+	if got := options[1].Default; got.Kind != NoDefault {
+		t.Errorf("--moored default got = %#v, want none", got)
+	}
+
+	if got := options[2].Default; got.Kind != DurationDefault || got.Value != 30*time.Second {
+		t.Errorf("--timeout default got = %#v, want 30s", got)
+	}
+
+	t.Run("when the default is not one of the choices", func(t *testing.T) {
+		lines := []string{
+			"## OPTIONS",
+			"  * `--format=<fmt>`:",
+			"    Output format. [choices: json, yaml] [default: xml]",
+			"  * `--formats=<fmts>`:",
+			"    Output formats. [choices: json, yaml] [default: json, toml]",
+			"  * `--moored`:",
+			"    Moored mine. [choices: yes, no]",
+		}
+
+		var got []string
+		for _, diagnostic := range RonnToDocopt(lines).Diagnostics {
+			got = append(got, diagnostic.String())
+		}
+
+		want := []string{
+			"2: option --format=<fmt>: default xml is not one of the choices json, yaml",
+			"4: option --formats=<fmts>: default toml is not one of the choices json, yaml",
+			"6: option --moored has choices, but takes no argument",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("diagnostics got = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package ronn2docopt
+
+import (
+	"fmt"
+)
+
+// A Diagnostic reports something the parser had to guess about, or could not resolve, at a (1 based) line of the ronn page.
+// File is only set once the diagnostic is located in its source, see Locate.
+// Code is the name of the lint rule that reports it, e.g. indentation.
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.File != "" {
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	}
+
+	return fmt.Sprintf("%d: %s", d.Line, d.Message)
+}
+
+// Locate moves the diagnostic from a line of the expanded page to the file and line it was read from
+func (d Diagnostic) Locate(sources SourceMap) Diagnostic {
+	source := sources.Locate(d.Line)
+	d.File, d.Line = source.File, source.Line
+
+	return d
+}
@@ -0,0 +1,266 @@
+package ronn2docopt
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A Document is a whole ronn page, parsed into sections and blocks.
+// Unlike DocOpt, which only keeps what docopt needs, it keeps all the content,
+// so that it can be rendered as a man page.
+type Document struct {
+	Name       string
+	ManSection string
+	Tagline    string
+	Sections   []DocumentSection
+}
+
+type DocumentSection struct {
+	Name   string
+	Blocks []Block
+}
+
+type BlockKind int
+
+const (
+	ParagraphBlock BlockKind = iota
+	DefinitionListBlock
+	ListBlock
+	CodeBlock
+	SubheadingBlock
+)
+
+// A Block is a paragraph, list, code block or sub-heading of a section.
+// Paragraphs, code blocks and sub-headings have Lines, lists have Items.
+type Block struct {
+	Kind  BlockKind
+	Lines []string
+	Items []ListItem
+}
+
+// A ListItem is a bullet of a list.
+// In a definition list, Term is the bullet text without the trailing colon, e.g. `--help`,
+// and Lines is the body. In a plain list, Lines is the bullet text followed by the body.
+// Paragraphs within Lines are separated by blank lines.
+type ListItem struct {
+	Term  string
+	Lines []string
+}
+
+var titleRe, titleMa = RegexAndMatchNames(`^(?:%\s*|#\s+)?(?P<name>[\w.:-]+)\((?P<section>\w+)\)\s+-{1,2}\s+(?P<tagline>.*)$`)
+var underlineRe = regexp.MustCompile(`^(=+|-+)\s*$`)
+var listItemRe, listItemMa = RegexAndMatchNames(`^(?P<indent> {0,3})[*+-] +(?P<text>.*)$`)
+var subheadingRe, subheadingMa = RegexAndMatchNames(`^###\s+(?P<heading>.*)$`)
+
+// ParseDocument parses a ronn page: the title line, e.g.
+//
+//	naval_fate(6) -- the naval fate game
+//
+// followed by sections, which are delimited by section headers
+func ParseDocument(lines []string) *Document {
+	doc := &Document{}
+
+	var current *DocumentSection
+	var sectionLines []string
+	titleFound := false
+
+	finishSection := func() {
+		if current != nil {
+			current.Blocks = parseBlocks(sectionLines)
+			doc.Sections = append(doc.Sections, *current)
+		}
+		sectionLines = nil
+	}
+
+	for _, line := range lines {
+		if r, name := isSectionHeader(line); r {
+			finishSection()
+			current = &DocumentSection{Name: strings.TrimSpace(name)}
+			continue
+		}
+
+		if current != nil {
+			sectionLines = append(sectionLines, line)
+			continue
+		}
+
+		if !titleFound {
+			if ma := NamedMatches(titleRe, titleMa, line); len(ma) > 0 {
+				doc.Name = ma["name"]
+				doc.ManSection = ma["section"]
+				doc.Tagline = strings.TrimSpace(ma["tagline"])
+				titleFound = true
+			}
+		}
+	}
+
+	finishSection()
+
+	return doc
+}
+
+// Section returns the first section with the given name, compared case-insensitively
+func (doc *Document) Section(name string) *DocumentSection {
+	for i := range doc.Sections {
+		if strings.EqualFold(doc.Sections[i].Name, name) {
+			return &doc.Sections[i]
+		}
+	}
+
+	return nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func parseBlocks(lines []string) []Block {
+	var blocks []Block
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "" || underlineRe.MatchString(line):
+			i++
+		case strings.HasPrefix(strings.TrimSpace(line), "```"):
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			// skip closing fence
+			i++
+			blocks = append(blocks, Block{Kind: CodeBlock, Lines: code})
+		case subheadingRe.MatchString(line):
+			ma := NamedMatches(subheadingRe, subheadingMa, line)
+			blocks = append(blocks, Block{Kind: SubheadingBlock, Lines: []string{strings.TrimSpace(ma["heading"])}})
+			i++
+		case listItemRe.MatchString(line):
+			var b Block
+			b, i = parseList(lines, i)
+			blocks = append(blocks, b)
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			var code []string
+			for ; i < len(lines) && (isIndented(lines[i]) || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, dedent(lines[i], 4))
+			}
+			blocks = append(blocks, Block{Kind: CodeBlock, Lines: trimBlankLines(code)})
+		default:
+			var paragraph []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if listItemRe.MatchString(lines[i]) || subheadingRe.MatchString(lines[i]) {
+					break
+				}
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+			}
+			blocks = append(blocks, Block{Kind: ParagraphBlock, Lines: paragraph})
+		}
+	}
+
+	return blocks
+}
+
+// Parses the list starting at lines[start], returning the list and the index of the line after it.
+// A list item continues with indented lines, and blank lines that are followed by indented lines.
+func parseList(lines []string, start int) (Block, int) {
+	b := Block{Kind: ListBlock}
+
+	i := start
+	for i < len(lines) {
+		ma := NamedMatches(listItemRe, listItemMa, lines[i])
+		if len(ma) == 0 {
+			break
+		}
+
+		text := strings.TrimSpace(ma["text"])
+		item := ListItem{}
+
+		if i == start && strings.HasSuffix(text, ":") {
+			b.Kind = DefinitionListBlock
+		}
+
+		if b.Kind == DefinitionListBlock {
+			item.Term = strings.TrimSuffix(text, ":")
+		} else {
+			item.Lines = append(item.Lines, text)
+		}
+
+		var body []string
+		for i++; i < len(lines); i++ {
+			if isIndented(lines[i]) && !listItemRe.MatchString(lines[i]) {
+				body = append(body, lines[i])
+				continue
+			}
+
+			if strings.TrimSpace(lines[i]) == "" && i+1 < len(lines) && isIndented(lines[i+1]) && !listItemRe.MatchString(lines[i+1]) {
+				body = append(body, "")
+				continue
+			}
+
+			break
+		}
+
+		item.Lines = append(item.Lines, dedentAll(body)...)
+		b.Items = append(b.Items, item)
+
+		// blank lines between items
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			if i+1 < len(lines) && listItemRe.MatchString(lines[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+	}
+
+	return b, i
+}
+
+// An indented line is a non-blank line starting with at least 4 spaces (or a tab)
+func isIndented(line string) bool {
+	return strings.TrimSpace(line) != "" && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"))
+}
+
+// Removes up to n leading spaces, or a single tab
+func dedent(line string, n int) string {
+	if strings.HasPrefix(line, "\t") {
+		return line[1:]
+	}
+
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+
+	return line[i:]
+}
+
+// Removes the indentation common to all non-blank lines
+func dedentAll(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		l := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == -1 || l < indent {
+			indent = l
+		}
+	}
+
+	var dedented []string
+	for _, line := range lines {
+		dedented = append(dedented, dedent(line, indent))
+	}
+
+	return dedented
+}
+
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
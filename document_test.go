@@ -0,0 +1,122 @@
+package ronn2docopt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	t.Run("title line", func(t *testing.T) {
+		doc := ParseDocument(exampleFile)
+
+		if doc.Name != "ronn" {
+			t.Errorf("doc.Name got = %s, want ronn", doc.Name)
+		}
+
+		if doc.ManSection != "1" {
+			t.Errorf("doc.ManSection got = %s, want 1", doc.ManSection)
+		}
+
+		if doc.Tagline != "convert markdown files to manpages" {
+			t.Errorf("doc.Tagline got = %s, want convert markdown files to manpages", doc.Tagline)
+		}
+	})
+
+	t.Run("when title uses percent sign", func(t *testing.T) {
+		doc := ParseDocument([]string{"% naval_fate(6) -- the naval fate game"})
+
+		if doc.Name != "naval_fate" || doc.ManSection != "6" {
+			t.Errorf("doc got = %+v, want naval_fate(6)", doc)
+		}
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		doc := ParseDocument(exampleFile)
+
+		var got []string
+		for _, s := range doc.Sections {
+			got = append(got, s.Name)
+		}
+
+		want := []string{"SYNOPSIS", "DESCRIPTION", "OPTIONS", "Another Section"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("sections got = %v, want %v", got, want)
+		}
+
+		if doc.Section("options") == nil {
+			t.Error("doc.Section(options) got = nil")
+		}
+	})
+
+	t.Run("options section blocks", func(t *testing.T) {
+		doc := ParseDocument(exampleFile)
+		blocks := doc.Section("OPTIONS").Blocks
+
+		var got []BlockKind
+		for _, b := range blocks {
+			got = append(got, b.Kind)
+		}
+
+		want := []BlockKind{ParagraphBlock, DefinitionListBlock, ParagraphBlock, DefinitionListBlock}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("block kinds got = %v, want %v", got, want)
+		}
+
+		items := blocks[1].Items
+		if len(items) != 4 {
+			t.Fatalf("number of items got = %d, want 4", len(items))
+		}
+
+		if items[0].Term != "`-h`, `--help`" {
+			t.Errorf("item.Term got = %s, want `-h`, `--help`", items[0].Term)
+		}
+
+		wantLines := []string{
+			"Speed in knots. [default: 10]",
+			"The server respects the `--style` and document attribute options",
+			"(`--manual`, `--date`, etc.). These same options can be varied at request",
+			"",
+			"*NOTE: This is a note",
+		}
+		if !reflect.DeepEqual(items[2].Lines, wantLines) {
+			t.Errorf("item.Lines got = %q, want %q", items[2].Lines, wantLines)
+		}
+	})
+}
+
+func TestParseBlocks(t *testing.T) {
+	t.Run("when has code blocks and plain lists", func(t *testing.T) {
+		blocks := parseBlocks([]string{
+			"Some text",
+			"",
+			"    $ naval_fate ship new Guardian",
+			"",
+			"```",
+			"$ naval_fate --version",
+			"```",
+			"",
+			"  * first",
+			"  * second",
+			"",
+			"### Sub-heading",
+		})
+
+		var got []BlockKind
+		for _, b := range blocks {
+			got = append(got, b.Kind)
+		}
+
+		want := []BlockKind{ParagraphBlock, CodeBlock, CodeBlock, ListBlock, SubheadingBlock}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("block kinds got = %v, want %v", got, want)
+		}
+
+		if blocks[1].Lines[0] != "$ naval_fate ship new Guardian" {
+			t.Errorf("code got = %s", blocks[1].Lines[0])
+		}
+
+		if len(blocks[3].Items) != 2 || blocks[3].Items[1].Lines[0] != "second" {
+			t.Errorf("list items got = %+v", blocks[3].Items)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package ronn2docopt
+
+import (
+	"regexp"
+	"strings"
+)
+
+var envBindingRe = regexp.MustCompile(`\[env: ([^\]]+)\]`)
+var envDefaultRe = regexp.MustCompile(`\[default: .*\]`)
+
+// ApplyEnvironment makes environment variables the defaults of the options bound to them.
+// It works on a rendered usage string (see DocOpt.String), so it can be used at runtime
+// on an embedded usage string, right before handing it to docopt to parse argv.
+// Options bound with [env: NAME] get [default: value] when lookup finds NAME,
+// replacing the documented default, if any.
+func ApplyEnvironment(usage string, lookup func(string) (string, bool)) string {
+	lines := strings.Split(usage, "\n")
+
+	for i, line := range lines {
+		ma := envBindingRe.FindStringSubmatch(line)
+		if ma == nil {
+			continue
+		}
+
+		value, ok := lookup(ma[1])
+		if !ok {
+			continue
+		}
+
+		defaultValue := "[default: " + value + "]"
+
+		if envDefaultRe.MatchString(line) {
+			lines[i] = envDefaultRe.ReplaceAllLiteralString(line, defaultValue)
+		} else {
+			lines[i] = line + " " + defaultValue
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
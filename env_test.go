@@ -0,0 +1,120 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var environmentSection = []string{
+	"## ENVIRONMENT",
+	"",
+	"  * `NAVAL_SPEED`:",
+	"    Default speed in knots. Overrides `--speed`.",
+	"",
+	"  * `NAVAL_FLEET`:",
+	"    Name of the fleet",
+	"",
+}
+
+var exampleFileWithEnvironment = append(append([]string{}, exampleFile...), environmentSection...)
+
+func TestRonnToDocopt_Environment(t *testing.T) {
+	t.Run("example file with environment has 2 variables", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithEnvironment)
+
+		got := len(d.Environment)
+		want := 2
+		if got != want {
+			t.Errorf("number of environment variables got = %d, want %d", got, want)
+			return
+		}
+
+		if d.Environment[0].Option != "--speed" {
+			t.Errorf("environment.Option got = %s, want --speed", d.Environment[0].Option)
+		}
+
+		if d.Environment[1].Option != "" {
+			t.Errorf("environment.Option got = %s, want <empty>", d.Environment[1].Option)
+		}
+	})
+
+	t.Run("option is bound to the variable", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithEnvironment)
+
+		got := d.HelpOptionSections[0].Options[2].Env
+		want := "NAVAL_SPEED"
+		if got != want {
+			t.Errorf("option.Env got = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("help shows the binding before the default", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithEnvironment)
+		got := d.String()
+
+		want := "Usage:\n" +
+			"  naval_fate ship new <name>...\n" +
+			"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+			"  naval_fate ship shoot <x> <y>\n" +
+			"  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]\n" +
+			"  naval_fate -h | --help\n" +
+			"  naval_fate --version\n" +
+			"\n" +
+			"Options:\n" +
+			"  -h --help     Show this screen.\n" +
+			"  --version     Show version.\n" +
+			"  --speed=<kn>  Speed in knots. [env: NAVAL_SPEED] [default: 10]\n" +
+			"  --foo         Multiline description\n" +
+			"\n" +
+			"Other Options\n" +
+			"  -b  Thingy [default: baz]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	usage := "Options:\n" +
+		"  --speed=<kn>  Speed in knots. [env: NAVAL_SPEED] [default: 10]\n" +
+		"  --fleet=<f>   Fleet. [env: NAVAL_FLEET]\n" +
+		"  --foo         Foo"
+
+	t.Run("when variables are set", func(t *testing.T) {
+		env := map[string]string{"NAVAL_SPEED": "20", "NAVAL_FLEET": "red"}
+		got := ApplyEnvironment(usage, func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		})
+
+		want := "Options:\n" +
+			"  --speed=<kn>  Speed in knots. [env: NAVAL_SPEED] [default: 20]\n" +
+			"  --fleet=<f>   Fleet. [env: NAVAL_FLEET] [default: red]\n" +
+			"  --foo         Foo"
+
+		if got != want {
+			t.Errorf("got = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("when variables are not set", func(t *testing.T) {
+		got := ApplyEnvironment(usage, func(name string) (string, bool) {
+			return "", false
+		})
+
+		if got != usage {
+			t.Errorf("got = %s, want %s", got, usage)
+		}
+	})
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"github.com/docopt/docopt-go"
+	"github.com/ghostsquad/ronn2docopt"
 	"github.com/ghostsquad/ronn2docopt/examples/basic/lib"
+	"os"
 )
 
 func main() {
@@ -13,6 +15,9 @@ func main() {
 		panic("asset not found")
 	}
 
+	// options documented with [env: NAME] default to the value of $NAME
+	usage = ronn2docopt.ApplyEnvironment(usage, os.LookupEnv)
+
 	arguments, _ := docopt.Parse(usage, nil, true, "Naval Fate 2.0", false)
 	fmt.Println(arguments)
 }
\ No newline at end of file
@@ -0,0 +1,211 @@
+package ronn2docopt
+
+import (
+	"regexp"
+	"strings"
+)
+
+var flagSeparatorRe = regexp.MustCompile(`,\s*(-)`)
+
+// FormatRonn rewrites the SYNOPSIS and OPTIONS sections of a ronn page into canonical form.
+// Every other line of the page is left untouched.
+func FormatRonn(lines []string) []string {
+	return FormatRonnWithConfig(lines, DefaultConfig())
+}
+
+// FormatRonnWithConfig formats the sections named by the given config, see FormatRonn
+//
+// The canonical synopsis quotes the program and its arguments separately, with a <br> after every line but the last:
+//
+//	`naval_fate` `ship new <name>...`<br>
+//	`naval_fate` `--version`
+//
+// Canonical options quote each flag, e.g. "`-h`, `--help`:", with two-space bullets, four-space bodies,
+// and a single blank line between options and description paragraphs.
+func FormatRonnWithConfig(lines []string, config *Config) []string {
+	config = config.withDefaults()
+
+	formatted := append([]string{}, lines...)
+
+	// format the later section first, so that the start of the other stays valid
+	type section struct {
+		start  int
+		length int
+		format func([]string) []string
+	}
+
+	synopsis, synopsisStart := getFirstSectionAt(formatted, config.SynopsisSections...)
+	options, optionsStart := getFirstSectionAt(formatted, config.OptionsSections...)
+
+	sections := []section{
+		{synopsisStart, len(synopsis), formatSynopsisSection},
+		{optionsStart, len(options), formatOptionsSection},
+	}
+
+	if optionsStart < synopsisStart {
+		sections[0], sections[1] = sections[1], sections[0]
+	}
+
+	for i := len(sections) - 1; i >= 0; i-- {
+		s := sections[i]
+		if s.start == -1 {
+			continue
+		}
+
+		body := formatted[s.start : s.start+s.length]
+		last := s.start+s.length == len(formatted)
+
+		replacement := s.format(body)
+		if !last {
+			replacement = append(replacement, "")
+		}
+
+		rest := append([]string{}, formatted[s.start+s.length:]...)
+		formatted = append(append(formatted[:s.start], replacement...), rest...)
+	}
+
+	return formatted
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func formatSynopsisSection(lines []string) []string {
+	var usages []string
+
+	for _, line := range lines {
+		line = strings.Replace(line, "`", "", -1)
+		line = brRe.ReplaceAllString(line, "$1")
+		line = strings.Join(strings.Fields(line), " ")
+
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+
+		usage := "`" + parts[0] + "`"
+		if len(parts) > 1 {
+			usage += " `" + parts[1] + "`"
+		}
+
+		usages = append(usages, usage)
+	}
+
+	for i := 0; i < len(usages)-1; i++ {
+		usages[i] += "<br>"
+	}
+
+	return append([]string{""}, usages...)
+}
+
+// An options section is a sequence of description paragraphs and options
+type formatUnit struct {
+	declaration string
+	lines       []string
+}
+
+func formatOptionsSection(lines []string) []string {
+	var units []*formatUnit
+	var current *formatUnit
+
+	for _, line := range expandTabLines(lines) {
+		line = strings.TrimRight(line, " ")
+
+		if f, declaration := formatOptionDeclaration(line); f {
+			current = &formatUnit{declaration: declaration}
+			units = append(units, current)
+			continue
+		}
+
+		if line == "" {
+			if current != nil {
+				current.lines = append(current.lines, "")
+			}
+			continue
+		}
+
+		// description paragraphs start at the beginning of the line
+		if isSectionDescriptionLine(line) && (current == nil || current.declaration != "") {
+			current = &formatUnit{}
+			units = append(units, current)
+		}
+
+		if current == nil {
+			current = &formatUnit{}
+			units = append(units, current)
+		}
+
+		current.lines = append(current.lines, line)
+	}
+
+	formatted := []string{""}
+
+	for i, u := range units {
+		if i > 0 {
+			formatted = append(formatted, "")
+		}
+
+		if u.declaration == "" {
+			formatted = append(formatted, collapseBlankLines(u.lines)...)
+			continue
+		}
+
+		formatted = append(formatted, u.declaration)
+		for _, line := range collapseBlankLines(dedentAll(u.lines)) {
+			if line != "" {
+				line = "    " + line
+			}
+			formatted = append(formatted, line)
+		}
+	}
+
+	return formatted
+}
+
+// Quotes each flag of an option declaration, e.g. "`-h, --help`:" becomes "`-h`, `--help`:"
+func formatOptionDeclaration(line string) (bool, string) {
+	ma := NamedMatches(namedOptionRe, namedOptionMa, line)
+	if len(ma) == 0 {
+		return false, ""
+	}
+
+	// commas separate flags, but can also be part of an argument, e.g. --style=<module>[,<module>]...
+	name := strings.Replace(ma["name"], "`", "", -1)
+	name = flagSeparatorRe.ReplaceAllString(name, " $1")
+
+	var flags []string
+	for _, t := range strings.Fields(name) {
+		if strings.HasPrefix(t, "-") || len(flags) == 0 {
+			flags = append(flags, t)
+			continue
+		}
+
+		flags[len(flags)-1] += " " + t
+	}
+
+	for i, f := range flags {
+		flags[i] = "`" + f + "`"
+	}
+
+	return true, "  * " + strings.Join(flags, ", ") + ":"
+}
+
+// Collapses runs of blank lines into one, and removes leading and trailing blank lines
+func collapseBlankLines(lines []string) []string {
+	var collapsed []string
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(collapsed) == 0 || collapsed[len(collapsed)-1] == "" {
+				continue
+			}
+			line = ""
+		}
+
+		collapsed = append(collapsed, line)
+	}
+
+	return trimBlankLines(collapsed)
+}
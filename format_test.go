@@ -0,0 +1,129 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var unformattedFile = []string{
+	"naval_fate(6) -- the naval fate game",
+	"",
+	"## SYNOPSIS",
+	"`naval_fate ship new <name>...`<br>",
+	"",
+	"",
+	"naval_fate  `--version`<br>",
+	"## DESCRIPTION",
+	"",
+	"  Left   *alone*  ",
+	"",
+	"## OPTIONS",
+	"These options control the game.",
+	"  * `-h, --help`:",
+	"      Show this screen.",
+	"",
+	"",
+	"  * `--speed`=<kn>:   ",
+	"   Speed in knots. [default: 10]",
+	"",
+	"      $ naval_fate --speed=20",
+	"  * --style=<module>[,<module>]...:",
+	"    Styles.",
+	"Other Options",
+	"  * `-b`:",
+	"    Thingy",
+	"## SEE ALSO",
+	"docopt(7)",
+}
+
+func TestFormatRonn(t *testing.T) {
+	t.Run("canonical form", func(t *testing.T) {
+		got := strings.Join(FormatRonn(unformattedFile), "\n")
+
+		want := strings.Join([]string{
+			"naval_fate(6) -- the naval fate game",
+			"",
+			"## SYNOPSIS",
+			"",
+			"`naval_fate` `ship new <name>...`<br>",
+			"`naval_fate` `--version`",
+			"",
+			"## DESCRIPTION",
+			"",
+			"  Left   *alone*  ",
+			"",
+			"## OPTIONS",
+			"",
+			"These options control the game.",
+			"",
+			"  * `-h`, `--help`:",
+			"    Show this screen.",
+			"",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots. [default: 10]",
+			"",
+			"       $ naval_fate --speed=20",
+			"",
+			"  * `--style=<module>[,<module>]...`:",
+			"    Styles.",
+			"",
+			"Other Options",
+			"",
+			"  * `-b`:",
+			"    Thingy",
+			"",
+			"## SEE ALSO",
+			"docopt(7)",
+		}, "\n")
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		once := FormatRonn(unformattedFile)
+		twice := FormatRonn(once)
+
+		if !reflect.DeepEqual(once, twice) {
+			t.Errorf("formatting twice got = %q, want %q", twice, once)
+		}
+	})
+
+	t.Run("converts the same as the original", func(t *testing.T) {
+		got := RonnToDocopt(FormatRonn(exampleFile)).String()
+		want := RonnToDocopt(exampleFile).String()
+
+		if got != want {
+			t.Errorf("got = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestFormatOptionDeclaration(t *testing.T) {
+	tests := map[string]string{
+		"  * `-h`, `--help`:":            "  * `-h`, `--help`:",
+		"  * `-h, --help`:":              "  * `-h`, `--help`:",
+		"  * -h,--help:":                 "  * `-h`, `--help`:",
+		"  * `-s <kn>`, `--speed=<kn>`:": "  * `-s <kn>`, `--speed=<kn>`:",
+	}
+
+	for in, want := range tests {
+		_, got := formatOptionDeclaration(in)
+		if got != want {
+			t.Errorf("formatOptionDeclaration(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
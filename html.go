@@ -0,0 +1,222 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// HTMLOptions control the HTML rendering of a document.
+// A Fragment is only the manual's content, without <html>, <head> or <body>, for embedding in another page.
+// Stylesheet is CSS embedded in a <style> element, DefaultStylesheet can be used as a starting point.
+type HTMLOptions struct {
+	Fragment   bool
+	Stylesheet string
+}
+
+const DefaultStylesheet = `.mp { max-width: 78ex; margin: 0 auto; font-family: monospace; line-height: 1.3; }
+.mp h2 { font-size: 100%; margin: 1.5em 0 0.5em -4ex; }
+.mp h3 { font-size: 100%; margin: 1em 0 0.5em -2ex; }
+.mp code, .mp strong { font-weight: bold; }
+.mp var { font-style: italic; }
+.mp dt { margin-top: 1em; }
+.mp dd { margin-left: 4ex; }
+.mp dd p { margin: 0.25em 0; }
+.mp pre { margin-left: 4ex; }
+.mp a[href] { color: inherit; }`
+
+var htmlCodeVariableRe = regexp.MustCompile(`&lt;([\w-]+)&gt;`)
+var htmlAnchorRe = regexp.MustCompile(`[^\w-]+`)
+
+// HTML renders the document as an HTML manual page.
+// Each option of a definition list gets an anchor per flag, e.g. #option--speed and #option-s.
+func (doc *Document) HTML(options HTMLOptions) string {
+	var buffer bytes.Buffer
+
+	if !options.Fragment {
+		buffer.WriteString("<!DOCTYPE html>\n")
+		buffer.WriteString("<html>\n")
+		buffer.WriteString("<head>\n")
+		buffer.WriteString("  <meta http-equiv=\"content-type\" content=\"text/html;charset=utf8\">\n")
+		buffer.WriteString("  <meta name=\"generator\" content=\"ronn2docopt\">\n")
+		buffer.WriteString("  <title>" + html.EscapeString(doc.title()) + "</title>\n")
+
+		if options.Stylesheet != "" {
+			buffer.WriteString("  <style type=\"text/css\">\n")
+			buffer.WriteString(options.Stylesheet + "\n")
+			buffer.WriteString("  </style>\n")
+		}
+
+		buffer.WriteString("</head>\n")
+		buffer.WriteString("<body>\n")
+	} else if options.Stylesheet != "" {
+		buffer.WriteString("<style type=\"text/css\">\n")
+		buffer.WriteString(options.Stylesheet + "\n")
+		buffer.WriteString("</style>\n")
+	}
+
+	buffer.WriteString("<div class=\"mp\" id=\"man\">\n")
+
+	if doc.Name != "" {
+		buffer.WriteString("<section id=\"NAME\">\n")
+		buffer.WriteString("<h2>NAME</h2>\n")
+		buffer.WriteString("<p class=\"man-name\"><code>" + html.EscapeString(doc.Name) + "</code>")
+		if doc.Tagline != "" {
+			buffer.WriteString(" - <span class=\"man-whatis\">" + htmlInline(doc.Tagline) + "</span>")
+		}
+		buffer.WriteString("</p>\n")
+		buffer.WriteString("</section>\n")
+	}
+
+	for _, s := range doc.Sections {
+		buffer.WriteString("<section id=\"" + htmlAnchor(s.Name) + "\">\n")
+		buffer.WriteString("<h2>" + html.EscapeString(s.Name) + "</h2>\n")
+
+		for _, b := range s.Blocks {
+			writeHTMLBlock(&buffer, b)
+		}
+
+		buffer.WriteString("</section>\n")
+	}
+
+	buffer.WriteString("</div>\n")
+
+	if !options.Fragment {
+		buffer.WriteString("</body>\n")
+		buffer.WriteString("</html>\n")
+	}
+
+	return buffer.String()
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// e.g. naval_fate(6) - the naval fate game
+func (doc *Document) title() string {
+	title := doc.Name
+	if doc.ManSection != "" {
+		title += "(" + doc.ManSection + ")"
+	}
+
+	if doc.Tagline != "" {
+		title += " - " + doc.Tagline
+	}
+
+	return title
+}
+
+func writeHTMLBlock(buffer *bytes.Buffer, b Block) {
+	switch b.Kind {
+	case ParagraphBlock:
+		writeHTMLParagraphs(buffer, b.Lines)
+	case SubheadingBlock:
+		buffer.WriteString("<h3 id=\"" + htmlAnchor(b.Lines[0]) + "\">" + html.EscapeString(b.Lines[0]) + "</h3>\n")
+	case CodeBlock:
+		buffer.WriteString("<pre><code>")
+		buffer.WriteString(html.EscapeString(strings.Join(b.Lines, "\n")))
+		buffer.WriteString("</code></pre>\n")
+	case DefinitionListBlock:
+		buffer.WriteString("<dl>\n")
+		for _, item := range b.Items {
+			buffer.WriteString("<dt")
+
+			// the first flag anchors the term, other flags get their own (empty) anchors
+			flags := optionFlags(strings.Replace(item.Term, "`", "", -1))
+			if len(flags) > 0 {
+				buffer.WriteString(" id=\"" + optionAnchor(flags[0]) + "\">")
+				for _, f := range flags[1:] {
+					buffer.WriteString("<a id=\"" + optionAnchor(f) + "\"></a>")
+				}
+			} else {
+				buffer.WriteString(">")
+			}
+
+			buffer.WriteString(htmlInline(item.Term))
+			buffer.WriteString("</dt>\n")
+			buffer.WriteString("<dd>")
+			writeHTMLParagraphs(buffer, item.Lines)
+			buffer.WriteString("</dd>\n")
+		}
+		buffer.WriteString("</dl>\n")
+	case ListBlock:
+		buffer.WriteString("<ul>\n")
+		for _, item := range b.Items {
+			buffer.WriteString("<li>")
+			writeHTMLParagraphs(buffer, item.Lines)
+			buffer.WriteString("</li>\n")
+		}
+		buffer.WriteString("</ul>\n")
+	}
+}
+
+// Writes paragraphs separated by blank lines
+func writeHTMLParagraphs(buffer *bytes.Buffer, lines []string) {
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+
+		buffer.WriteString("<p>" + strings.Join(paragraph, "\n") + "</p>\n")
+		paragraph = nil
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+
+		if m := brRe.FindStringSubmatch(line); m != nil {
+			paragraph = append(paragraph, htmlInline(strings.TrimSpace(m[1]))+"<br>")
+			continue
+		}
+
+		paragraph = append(paragraph, htmlInline(line))
+	}
+
+	flush()
+}
+
+// Converts ronn inline markup to HTML:
+// `code`, **strong**, _emphasis_, <variables> and [links](url)
+func htmlInline(s string) string {
+	var buffer bytes.Buffer
+
+	for _, in := range ParseInline(s) {
+		text := html.EscapeString(in.Text)
+
+		switch in.Kind {
+		case CodeInline:
+			buffer.WriteString("<code>" + htmlCodeVariableRe.ReplaceAllString(text, "<var>$1</var>") + "</code>")
+		case StrongInline:
+			buffer.WriteString("<strong>" + text + "</strong>")
+		case EmphasisInline:
+			buffer.WriteString("<em>" + text + "</em>")
+		case VariableInline:
+			buffer.WriteString("<var>" + text + "</var>")
+		case LinkInline:
+			buffer.WriteString("<a href=\"" + html.EscapeString(in.URL) + "\">" + text + "</a>")
+		default:
+			buffer.WriteString(text)
+		}
+	}
+
+	return buffer.String()
+}
+
+// e.g. "Another Section" returns Another-Section
+func htmlAnchor(s string) string {
+	return strings.Trim(htmlAnchorRe.ReplaceAllString(strings.TrimSpace(s), "-"), "-")
+}
+
+// e.g. --speed returns option--speed, -s returns option-s
+func optionAnchor(flag string) string {
+	return "option" + flag
+}
@@ -0,0 +1,114 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var htmlExampleFile = []string{
+	"naval_fate(6) -- the naval fate game",
+	"",
+	"## SYNOPSIS",
+	"",
+	"`naval_fate` `ship new <name>...`<br>",
+	"`naval_fate` `--version`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `-s`, `--speed=<kn>`:",
+	"    Speed in **knots**. See [docopt](http://docopt.org).",
+	"",
+	"## SEE ALSO",
+	"",
+	"  * docopt(7)",
+	"",
+}
+
+func TestDocument_HTML(t *testing.T) {
+	t.Run("fragment", func(t *testing.T) {
+		got := ParseDocument(htmlExampleFile).HTML(HTMLOptions{Fragment: true})
+
+		want := "<div class=\"mp\" id=\"man\">\n" +
+			"<section id=\"NAME\">\n" +
+			"<h2>NAME</h2>\n" +
+			"<p class=\"man-name\"><code>naval_fate</code> - <span class=\"man-whatis\">the naval fate game</span></p>\n" +
+			"</section>\n" +
+			"<section id=\"SYNOPSIS\">\n" +
+			"<h2>SYNOPSIS</h2>\n" +
+			"<p><code>naval_fate</code> <code>ship new <var>name</var>...</code><br>\n" +
+			"<code>naval_fate</code> <code>--version</code></p>\n" +
+			"</section>\n" +
+			"<section id=\"OPTIONS\">\n" +
+			"<h2>OPTIONS</h2>\n" +
+			"<dl>\n" +
+			"<dt id=\"option-s\"><a id=\"option--speed\"></a><code>-s</code>, <code>--speed=<var>kn</var></code></dt>\n" +
+			"<dd><p>Speed in <strong>knots</strong>. See <a href=\"http://docopt.org\">docopt</a>.</p>\n" +
+			"</dd>\n" +
+			"</dl>\n" +
+			"</section>\n" +
+			"<section id=\"SEE-ALSO\">\n" +
+			"<h2>SEE ALSO</h2>\n" +
+			"<ul>\n" +
+			"<li><p>docopt(7)</p>\n" +
+			"</li>\n" +
+			"</ul>\n" +
+			"</section>\n" +
+			"</div>\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("full page with stylesheet", func(t *testing.T) {
+		got := ParseDocument(htmlExampleFile).HTML(HTMLOptions{Stylesheet: DefaultStylesheet})
+
+		for _, want := range []string{
+			"<!DOCTYPE html>\n",
+			"<title>naval_fate(6) - the naval fate game</title>\n",
+			"<style type=\"text/css\">\n" + DefaultStylesheet + "\n",
+			"</body>\n</html>\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("html does not contain %q", want)
+			}
+		}
+	})
+
+	t.Run("full page without stylesheet", func(t *testing.T) {
+		got := ParseDocument(htmlExampleFile).HTML(HTMLOptions{})
+
+		if strings.Contains(got, "<style") {
+			t.Error("html contains <style>, want none")
+		}
+	})
+}
+
+func TestHTMLInline(t *testing.T) {
+	tests := map[string]string{
+		"plain text":      "plain text",
+		"`a < b`":         "<code>a &lt; b</code>",
+		"_emphasis_":      "<em>emphasis</em>",
+		"<file>":          "<var>file</var>",
+		"&lt;literal&gt;": "&lt;literal&gt;",
+		"[ENVIRONMENT][]": "<a href=\"#ENVIRONMENT\">ENVIRONMENT</a>",
+	}
+
+	for in, want := range tests {
+		got := htmlInline(in)
+		if got != want {
+			t.Errorf("htmlInline(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// e.g. <!-- include: common-options.ronn -->
+var includeRe, includeMa = RegexAndMatchNames(`^\s*<!--\s*include:\s*(?P<path>.+?)\s*-->\s*$`)
+
+// A SourceLine is the file and (1 based) line a line of an expanded page comes from
+type SourceLine struct {
+	File string
+	Line int
+}
+
+// A SourceMap has the SourceLine of each line of an expanded page
+type SourceMap []SourceLine
+
+// Locate returns where the (1 based) line of the expanded page comes from
+func (m SourceMap) Locate(line int) SourceLine {
+	if line < 1 || line > len(m) {
+		return SourceLine{Line: line}
+	}
+
+	return m[line-1]
+}
+
+// ReadRonnFileWithIncludes reads a ronn file, replacing include directives, e.g.
+//
+//	<!-- include: common-options.ronn -->
+//
+// with the lines of the included file. Paths are relative to the including file, and includes can be nested.
+// The source map locates each line in the file it was read from.
+func ReadRonnFileWithIncludes(ronnFile string) ([]string, SourceMap, error) {
+	var lines []string
+	var sources SourceMap
+
+	err := expandIncludes(ronnFile, nil, &lines, &sources)
+
+	return lines, sources, err
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// stack is the chain of files including this one, to detect cycles
+func expandIncludes(ronnFile string, stack []string, lines *[]string, sources *SourceMap) error {
+	abs, err := filepath.Abs(ronnFile)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range stack {
+		if s == abs {
+			cycle := append(append([]string{}, stack[i:]...), abs)
+			for j := range cycle {
+				cycle[j] = filepath.Base(cycle[j])
+			}
+
+			return fmt.Errorf("include cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	fileLines, err := ReadRonnFile(ronnFile)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range fileLines {
+		ma := NamedMatches(includeRe, includeMa, line)
+		if len(ma) == 0 {
+			*lines = append(*lines, line)
+			*sources = append(*sources, SourceLine{File: ronnFile, Line: i + 1})
+			continue
+		}
+
+		include := ma["path"]
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(ronnFile), include)
+		}
+
+		if err := expandIncludes(include, append(stack, abs), lines, sources); err != nil {
+			return fmt.Errorf("%s:%d: %s", ronnFile, i+1, err)
+		}
+	}
+
+	return nil
+}
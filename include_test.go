@@ -0,0 +1,134 @@
+package ronn2docopt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeRonnFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "ronn2docopt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestReadRonnFileWithIncludes(t *testing.T) {
+	dir := writeRonnFiles(t, map[string]string{
+		"docs/naval_fate.1.ronn": "## OPTIONS\n  * `--speed=<kn>`:\n    Speed in knots.\n<!-- include: ../common/options.ronn -->\n",
+		"common/options.ronn":    "  * `--verbose`:\n    Verbose output.\n<!-- include: log.ronn -->\n",
+		"common/log.ronn":        "  * `--log-level=<level>`:\n  \tLog level. [default: info]\n",
+		"cycle/a.ronn":           "## OPTIONS\n<!-- include: b.ronn -->\n",
+		"cycle/b.ronn":           "<!-- include: a.ronn -->\n",
+	})
+	defer os.RemoveAll(dir)
+
+	page := filepath.Join(dir, "docs", "naval_fate.1.ronn")
+
+	t.Run("when includes are nested", func(t *testing.T) {
+		lines, sources, err := ReadRonnFileWithIncludes(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, s := range RonnToDocopt(lines).HelpOptionSections {
+			for _, o := range s.Options {
+				names = append(names, o.Name)
+			}
+		}
+
+		want := []string{"--speed=<kn>", "--verbose", "--log-level=<level>"}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("options got = %q, want %q", names, want)
+		}
+
+		got := sources.Locate(6)
+		wantSource := SourceLine{File: filepath.Join(dir, "docs", "../common/log.ronn"), Line: 1}
+		if got != wantSource {
+			t.Errorf("source of line 6 got = %v, want %v", got, wantSource)
+		}
+	})
+
+	t.Run("diagnostics are located in the included file", func(t *testing.T) {
+		lines, sources, err := ReadRonnFileWithIncludes(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := RonnToDocopt(lines)
+		if len(d.Diagnostics) != 1 {
+			t.Fatalf("number of diagnostics got = %d, want 1: %v", len(d.Diagnostics), d.Diagnostics)
+		}
+
+		got := d.Diagnostics[0].Locate(sources).String()
+		want := filepath.Join(dir, "common", "log.ronn") + ":2: indentation mixes tabs and spaces, tabs are expanded to 4 columns"
+		if got != want {
+			t.Errorf("diagnostic got = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("when includes form a cycle", func(t *testing.T) {
+		_, _, err := ReadRonnFileWithIncludes(filepath.Join(dir, "cycle", "a.ronn"))
+		if err == nil {
+			t.Fatal("error got = nil, want include cycle")
+		}
+
+		want := "include cycle a.ronn -> b.ronn -> a.ronn"
+		if !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("error got = %s, want suffix %s", err, want)
+		}
+	})
+
+	t.Run("when an included file does not exist", func(t *testing.T) {
+		dir := writeRonnFiles(t, map[string]string{
+			"page.ronn": "## OPTIONS\n<!-- include: missing.ronn -->\n",
+		})
+		defer os.RemoveAll(dir)
+
+		_, _, err := ReadRonnFileWithIncludes(filepath.Join(dir, "page.ronn"))
+		if err == nil || !strings.Contains(err.Error(), "page.ronn:2: ") {
+			t.Errorf("error got = %v, want the including line", err)
+		}
+	})
+}
+
+func TestConvertRonnFile(t *testing.T) {
+	t.Run("when the page includes its options", func(t *testing.T) {
+		dir := writeRonnFiles(t, map[string]string{
+			"tool.1.ronn":  "## SYNOPSIS\n\n`tool` `[options] <file>`\n\n## OPTIONS\n\n<!-- include: options.ronn -->\n",
+			"options.ronn": "  * `--verbose`:\n    Verbose output.\n",
+		})
+		defer os.RemoveAll(dir)
+
+		got, err := ConvertRonnFile(filepath.Join(dir, "tool.1.ronn"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "Usage:\n" +
+			"  tool [options] <file>\n" +
+			"\n" +
+			"Options:\n" +
+			"  --verbose  Verbose output."
+		if got != want {
+			t.Errorf("usage got = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+type InlineKind int
+
+const (
+	TextInline InlineKind = iota
+	CodeInline
+	StrongInline
+	EmphasisInline
+	VariableInline
+	LinkInline
+)
+
+// An Inline is a span of ronn inline markup.
+// Text is the content without markup, with entities and backslash escapes resolved, and Raw is the source.
+// Links have a URL, reference links e.g. [ENVIRONMENT][] link to #ENVIRONMENT.
+type Inline struct {
+	Kind InlineKind
+	Text string
+	URL  string
+	Raw  string
+}
+
+var inlineVariableRe = regexp.MustCompile(`^<([\w-]+)>`)
+var inlineEntityRe = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z]+);`)
+var inlineLinkRe = regexp.MustCompile(`^\[([^\]]+)\]\(([^)]*)\)`)
+var inlineReferenceLinkRe = regexp.MustCompile(`^\[([^\]]+)\]\[([^\]]*)\]`)
+
+// like ronn, variables within code are still variables, e.g. `--speed=<kn>`
+var codeVariableRe = regexp.MustCompile(`<([\w-]+)>`)
+
+const (
+	ansiBold        = "\x1b[1m"
+	ansiNormal      = "\x1b[22m"
+	ansiUnderline   = "\x1b[4m"
+	ansiNoUnderline = "\x1b[24m"
+)
+
+// ParseInline splits a line into spans of inline markup:
+// `code`, **strong**, _emphasis_ (or *emphasis*), <variables>, [links](url) and [references][].
+// Nested markup is flattened into the Text of the outer span.
+func ParseInline(s string) []Inline {
+	var spans []Inline
+	var text, raw bytes.Buffer
+
+	flush := func() {
+		if raw.Len() > 0 {
+			spans = append(spans, Inline{Kind: TextInline, Text: text.String(), Raw: raw.String()})
+			text.Reset()
+			raw.Reset()
+		}
+	}
+
+	span := func(in Inline) {
+		flush()
+		spans = append(spans, in)
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isASCIIPunctuation(rest[1]):
+			text.WriteByte(rest[1])
+			raw.WriteString(rest[:2])
+			i += 2
+			continue
+		case rest[0] == '`':
+			if n := codeSpanLength(rest); n > 0 {
+				fence := len(rest) - len(strings.TrimLeft(rest, "`"))
+				span(Inline{Kind: CodeInline, Text: rest[fence : n-fence], Raw: rest[:n]})
+				i += n
+				continue
+			}
+		case strings.HasPrefix(rest, "**"):
+			if end := strings.Index(rest[2:], "**"); end > 0 {
+				span(Inline{Kind: StrongInline, Text: PlainInline(rest[2 : end+2]), Raw: rest[:end+4]})
+				i += end + 4
+				continue
+			}
+		case rest[0] == '_' || rest[0] == '*':
+			if n := emphasisLength(s, i); n > 0 {
+				span(Inline{Kind: EmphasisInline, Text: PlainInline(rest[1 : n-1]), Raw: rest[:n]})
+				i += n
+				continue
+			}
+		case rest[0] == '<':
+			if m := inlineVariableRe.FindStringSubmatch(rest); m != nil {
+				span(Inline{Kind: VariableInline, Text: m[1], Raw: m[0]})
+				i += len(m[0])
+				continue
+			}
+		case rest[0] == '[':
+			if m := inlineLinkRe.FindStringSubmatch(rest); m != nil {
+				span(Inline{Kind: LinkInline, Text: PlainInline(m[1]), URL: m[2], Raw: m[0]})
+				i += len(m[0])
+				continue
+			}
+
+			if m := inlineReferenceLinkRe.FindStringSubmatch(rest); m != nil {
+				ref := m[2]
+				if ref == "" {
+					ref = m[1]
+				}
+
+				span(Inline{Kind: LinkInline, Text: PlainInline(m[1]), URL: "#" + ref, Raw: m[0]})
+				i += len(m[0])
+				continue
+			}
+		case rest[0] == '&':
+			if m := inlineEntityRe.FindString(rest); m != "" {
+				text.WriteString(html.UnescapeString(m))
+				raw.WriteString(m)
+				i += len(m)
+				continue
+			}
+		}
+
+		text.WriteByte(rest[0])
+		raw.WriteByte(rest[0])
+		i++
+	}
+
+	flush()
+
+	return spans
+}
+
+// PlainInline removes the inline markup, for docopt usage strings.
+// Variables keep their angle brackets, e.g. <file>, as docopt uses them for arguments.
+func PlainInline(s string) string {
+	var buffer bytes.Buffer
+
+	for _, in := range ParseInline(s) {
+		if in.Kind == VariableInline {
+			buffer.WriteString("<" + in.Text + ">")
+			continue
+		}
+
+		buffer.WriteString(in.Text)
+	}
+
+	return buffer.String()
+}
+
+// ANSIInline converts the inline markup to terminal styles:
+// code and strong are bold, emphasis and variables are underlined
+func ANSIInline(s string) string {
+	var buffer bytes.Buffer
+
+	for _, in := range ParseInline(s) {
+		switch in.Kind {
+		case CodeInline, StrongInline:
+			buffer.WriteString(ansiBold + in.Text + ansiNormal)
+		case EmphasisInline:
+			buffer.WriteString(ansiUnderline + in.Text + ansiNoUnderline)
+		case VariableInline:
+			buffer.WriteString(ansiUnderline + "<" + in.Text + ">" + ansiNoUnderline)
+		default:
+			buffer.WriteString(in.Text)
+		}
+	}
+
+	return buffer.String()
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// Returns the first sentence of a line, cut at the first period, exclamation or question mark
+// that is not part of inline markup or escaped, e.g. "See `os.Exit`. More" returns "See `os.Exit`."
+func firstSentence(s string) (string, bool) {
+	var buffer bytes.Buffer
+
+	for _, in := range ParseInline(s) {
+		if in.Kind != TextInline {
+			buffer.WriteString(in.Raw)
+			continue
+		}
+
+		for i := 0; i < len(in.Raw); i++ {
+			c := in.Raw[i]
+			buffer.WriteByte(c)
+
+			if c == '\\' && i+1 < len(in.Raw) && isASCIIPunctuation(in.Raw[i+1]) {
+				i++
+				buffer.WriteByte(in.Raw[i])
+				continue
+			}
+
+			if strings.IndexByte(".!?", c) != -1 {
+				return buffer.String(), true
+			}
+		}
+	}
+
+	return s, false
+}
+
+// Returns the length of the code span at the start of s, or 0 when the backticks are not closed
+func codeSpanLength(s string) int {
+	fence := len(s) - len(strings.TrimLeft(s, "`"))
+
+	end := strings.Index(s[fence:], s[:fence])
+	if end <= 0 {
+		return 0
+	}
+
+	return fence + end + fence
+}
+
+// Returns the length of the emphasis starting at s[i], or 0 when it's not emphasis.
+// Like markdown, underscores within words are not emphasis, e.g. snake_case_name.
+func emphasisLength(s string, i int) int {
+	delimiter := s[i]
+
+	if i > 0 && (isWordByte(s[i-1]) || s[i-1] == '*') {
+		return 0
+	}
+
+	end := strings.IndexAny(s[i+1:], "_*")
+	if end <= 0 || s[i+1+end] != delimiter {
+		return 0
+	}
+
+	after := i + 1 + end + 1
+	if after < len(s) && (isWordByte(s[after]) || s[after] == '*') {
+		return 0
+	}
+
+	return after - i
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isASCIIPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) != -1
+}
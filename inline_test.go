@@ -0,0 +1,57 @@
+package ronn2docopt
+
+import (
+	"testing"
+)
+
+func TestPlainInline(t *testing.T) {
+	tests := map[string]string{
+		"plain text":                      "plain text",
+		"`--speed=<kn>`":                  "--speed=<kn>",
+		"**strong** and _emphasis_":       "strong and emphasis",
+		"*emphasis*":                      "emphasis",
+		"snake_case_name":                 "snake_case_name",
+		"<file>":                          "<file>",
+		"&lt;literal&gt; &amp; more":      "<literal> & more",
+		"see [docopt](http://docopt.org)": "see docopt",
+		"see [ENVIRONMENT][]":             "see ENVIRONMENT",
+		"\\*not emphasis\\*":              "*not emphasis*",
+		"back\\slash":                     "back\\slash",
+		"Speed. [default: 10]":            "Speed. [default: 10]",
+		"**unclosed":                      "**unclosed",
+	}
+
+	for in, want := range tests {
+		got := PlainInline(in)
+		if got != want {
+			t.Errorf("PlainInline(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestANSIInline(t *testing.T) {
+	got := ANSIInline("Write **bold** to _file_ <path>.")
+
+	want := "Write \x1b[1mbold\x1b[22m to \x1b[4mfile\x1b[24m \x1b[4m<path>\x1b[24m."
+
+	if got != want {
+		t.Errorf("got = %q, want %q", got, want)
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := map[string]string{
+		"Show version. More.":                   "Show version.",
+		"Calls `os.Exit`. More.":                "Calls `os.Exit`.",
+		"See [docs](http://x.io/a.html). More.": "See [docs](http://x.io/a.html).",
+		"Version 1\\.0 only. More.":             "Version 1\\.0 only.",
+		"No period":                             "No period",
+	}
+
+	for in, want := range tests {
+		got, _ := firstSentence(in)
+		if got != want {
+			t.Errorf("firstSentence(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package ronn2docopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"off", "info", "warning", "error"}
+
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+
+	return SeverityOff, fmt.Errorf("unknown severity %q, want one of %s", name, strings.Join(severityNames, ", "))
+}
+
+// A LintIssue is a style problem found by a LintRule, at a (1 based) line of the ronn page.
+// File is only set once the issue is located in its source, see Locate.
+type LintIssue struct {
+	File     string   `json:"file,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.File != "" {
+		return fmt.Sprintf("%s:%d: %s: %s (%s)", i.File, i.Line, i.Severity, i.Message, i.Rule)
+	}
+
+	return fmt.Sprintf("%d: %s: %s (%s)", i.Line, i.Severity, i.Message, i.Rule)
+}
+
+// Locate moves the issue from a line of the expanded page to the file and line it was read from
+func (i LintIssue) Locate(sources SourceMap) LintIssue {
+	source := sources.Locate(i.Line)
+	i.File, i.Line = source.File, source.Line
+
+	return i
+}
+
+// A LintRule checks a ronn page for one kind of style problem.
+// Rules only fill in the Line and Message of the issues they find.
+type LintRule interface {
+	Name() string
+	DefaultSeverity() Severity
+	Check(lines []string, d *DocOpt) []LintIssue
+}
+
+// LintConfig overrides the default severity of rules by name, SeverityOff disables a rule,
+// and sets the section names of the page
+type LintConfig struct {
+	Severities map[string]Severity
+
+	// the section names of the page, the default config when nil
+	Sections *Config
+}
+
+// ParseSetting applies a name=severity setting, e.g. option-order=off
+func (c *LintConfig) ParseSetting(setting string) error {
+	parts := strings.SplitN(setting, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid rule setting %q, want <rule>=<severity>", setting)
+	}
+
+	name := strings.TrimSpace(parts[0])
+	if LookupLintRule(name) == nil {
+		return fmt.Errorf("unknown rule %q", name)
+	}
+
+	severity, err := ParseSeverity(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return err
+	}
+
+	if c.Severities == nil {
+		c.Severities = map[string]Severity{}
+	}
+	c.Severities[name] = severity
+
+	return nil
+}
+
+// DefaultLintRules are the rules Lint runs, in the order they are reported for the same line
+var DefaultLintRules = []LintRule{
+	DescriptionPeriodRule{},
+	OptionOrderRule{},
+	MissingDefaultRule{},
+	DefaultSyntaxRule{},
+	&OptionLengthRule{Max: 30},
+	SynopsisArgumentsRule{},
+	DefaultValueRule{},
+	RepeatableOptionRule{},
+	HiddenOptionRule{},
+	IndentationRule{},
+}
+
+func LookupLintRule(name string) LintRule {
+	for _, r := range DefaultLintRules {
+		if r.Name() == name {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// Lint runs the given rules (or DefaultLintRules when nil) over a ronn page, returning the issues sorted by line
+func Lint(lines []string, rules []LintRule, config *LintConfig) []LintIssue {
+	var issues []LintIssue
+
+	if rules == nil {
+		rules = DefaultLintRules
+	}
+
+	if config == nil {
+		config = &LintConfig{}
+	}
+
+	d := RonnToDocoptWithConfig(lines, config.Sections)
+
+	for _, r := range rules {
+		severity := r.DefaultSeverity()
+		if s, ok := config.Severities[r.Name()]; ok {
+			severity = s
+		}
+
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, issue := range r.Check(lines, d) {
+			issue.Rule = r.Name()
+			issue.Severity = severity
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues
+}
+
+// ==================================================== //
+// RULES
+// ---------------------------------------------------- //
+
+// Option descriptions are cut at the first period, so a description without one uses the whole first line
+type DescriptionPeriodRule struct{}
+
+func (DescriptionPeriodRule) Name() string              { return "description-period" }
+func (DescriptionPeriodRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (DescriptionPeriodRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			if o.Desc == "" {
+				issues = append(issues, LintIssue{Line: o.Line, Message: fmt.Sprintf("%s has no description", o.Name)})
+				continue
+			}
+
+			desc := PlainInline(o.Desc)
+			if desc == "" || !strings.ContainsAny(desc[len(desc)-1:], ".!?") {
+				issues = append(issues, LintIssue{Line: o.Line, Message: fmt.Sprintf("description of %s does not end with a period", o.Name)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// Options within an option section should be in alphabetical order of their first long flag
+type OptionOrderRule struct{}
+
+func (OptionOrderRule) Name() string              { return "option-order" }
+func (OptionOrderRule) DefaultSeverity() Severity { return SeverityInfo }
+
+func (OptionOrderRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	for _, s := range d.HelpOptionSections {
+		for i := 1; i < len(s.Options); i++ {
+			prev, o := s.Options[i-1], s.Options[i]
+			if optionSortKey(o.Name) < optionSortKey(prev.Name) {
+				issues = append(issues, LintIssue{Line: o.Line, Message: fmt.Sprintf("%s should come before %s", o.Name, prev.Name)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// Options that take a value should document their default
+type MissingDefaultRule struct{}
+
+func (MissingDefaultRule) Name() string              { return "missing-default" }
+func (MissingDefaultRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (MissingDefaultRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			if o.DefaultValue == "" && optionTakesValue(o.Name) {
+				issues = append(issues, LintIssue{Line: o.Line, Message: fmt.Sprintf("%s takes a value but has no [default: ...]", o.Name)})
+			}
+		}
+	}
+
+	return issues
+}
+
+var defaultLikeRe = regexp.MustCompile(`(?i)[\[(]\s*d[ae]f[a-z]*\s*[:=]`)
+var defaultSyntaxRe = regexp.MustCompile(`(?i)\[default: [^\[\]]*\]`)
+
+// Defaults must be written exactly as [default: value], otherwise docopt ignores them
+type DefaultSyntaxRule struct{}
+
+func (DefaultSyntaxRule) Name() string              { return "default-syntax" }
+func (DefaultSyntaxRule) DefaultSeverity() Severity { return SeverityError }
+
+func (DefaultSyntaxRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	o, start := getFirstSectionAt(lines, d.Config.withDefaults().OptionsSections...)
+
+	for i, line := range o {
+		candidates := defaultLikeRe.FindAllStringIndex(line, -1)
+		valid := defaultSyntaxRe.FindAllStringIndex(line, -1)
+
+		for _, c := range candidates {
+			ok := false
+			for _, v := range valid {
+				if c[0] == v[0] {
+					ok = true
+				}
+			}
+
+			if !ok {
+				end := strings.IndexAny(line[c[0]+1:], "])")
+				text := line[c[0]:]
+				if end != -1 {
+					text = line[c[0] : c[0]+end+2]
+				}
+
+				issues = append(issues, LintIssue{Line: start + i + 1, Message: fmt.Sprintf("malformed default %q, want [default: <value>]", text)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// Long option names push the descriptions of the whole option section to the right
+type OptionLengthRule struct {
+	Max int
+}
+
+func (*OptionLengthRule) Name() string              { return "option-length" }
+func (*OptionLengthRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r *OptionLengthRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			if len(o.Name) > r.Max {
+				issues = append(issues, LintIssue{Line: o.Line, Message: fmt.Sprintf("%s is longer than %d characters", o.Name, r.Max)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// Documented arguments must match the synopsis placeholders, see DocOpt.Validate
+type SynopsisArgumentsRule struct{}
+
+func (SynopsisArgumentsRule) Name() string              { return "synopsis-arguments" }
+func (SynopsisArgumentsRule) DefaultSeverity() Severity { return SeverityError }
+
+func (SynopsisArgumentsRule) Check(lines []string, d *DocOpt) []LintIssue {
+	var issues []LintIssue
+
+	_, start := getFirstSectionAt(lines, d.Config.withDefaults().ArgumentsSections...)
+
+	for _, err := range d.Validate() {
+		issues = append(issues, LintIssue{Line: start, Message: err.Error()})
+	}
+
+	return issues
+}
+
+// Defaults must have a value of their option's type, and only options that take a value have defaults, see ParseDefault
+type DefaultValueRule struct{}
+
+func (DefaultValueRule) Name() string              { return "default-value" }
+func (DefaultValueRule) DefaultSeverity() Severity { return SeverityError }
+
+func (r DefaultValueRule) Check(lines []string, d *DocOpt) []LintIssue {
+	return diagnosticIssues(d, r.Name())
+}
+
+// Options marked [repeatable] must be used in the synopsis, so that docopt can repeat them
+type RepeatableOptionRule struct{}
+
+func (RepeatableOptionRule) Name() string              { return "repeatable-option" }
+func (RepeatableOptionRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r RepeatableOptionRule) Check(lines []string, d *DocOpt) []LintIssue {
+	return diagnosticIssues(d, r.Name())
+}
+
+// Options marked [hidden] must be used in the synopsis, otherwise they stay listed for docopt to accept them
+type HiddenOptionRule struct{}
+
+func (HiddenOptionRule) Name() string              { return "hidden-option" }
+func (HiddenOptionRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r HiddenOptionRule) Check(lines []string, d *DocOpt) []LintIssue {
+	return diagnosticIssues(d, r.Name())
+}
+
+// Option bullets and bodies should be indented consistently, see DocOpt.Diagnostics
+type IndentationRule struct{}
+
+func (IndentationRule) Name() string              { return "indentation" }
+func (IndentationRule) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r IndentationRule) Check(lines []string, d *DocOpt) []LintIssue {
+	return diagnosticIssues(d, r.Name())
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// The diagnostics of the parser with the given code
+func diagnosticIssues(d *DocOpt, code string) []LintIssue {
+	var issues []LintIssue
+
+	for _, diagnostic := range d.Diagnostics {
+		if diagnostic.Code == code {
+			issues = append(issues, LintIssue{Line: diagnostic.Line, Message: diagnostic.Message})
+		}
+	}
+
+	return issues
+}
+
+// Sorts by the first long flag if any, e.g. "-h --help" sorts as help
+func optionSortKey(name string) string {
+	flags := optionFlags(name)
+	if len(flags) == 0 {
+		return name
+	}
+
+	key := flags[0]
+	for _, f := range flags {
+		if strings.HasPrefix(f, "--") {
+			key = f
+			break
+		}
+	}
+
+	return strings.ToLower(strings.TrimLeft(key, "-"))
+}
+
+// An option takes a value when it has an argument, e.g. --speed=<kn> or -s <kn>
+func optionTakesValue(name string) bool {
+	for _, f := range splitOptionName(name) {
+		if strings.ContainsAny(f, "= ") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,193 @@
+package ronn2docopt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var lintExampleFile = []string{
+	"naval_fate(6) -- the naval fate game",
+	"",
+	"## SYNOPSIS",
+	"",
+	"`naval_fate` `ship <name> move [--speed=<kn>]`<br>",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `--version`:",
+	"    Show version.",
+	"",
+	"  * `--speed=<kn>`:",
+	"    Speed in knots [defualt: 10]",
+	"",
+	"  * `--a-very-long-option-name-indeed=<value>`:",
+	"    Long. [default: [10]",
+	"",
+}
+
+func TestLint(t *testing.T) {
+	t.Run("default rules", func(t *testing.T) {
+		issues := Lint(lintExampleFile, nil, nil)
+
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+
+		want := []string{
+			"12: warning: description of --speed=<kn> does not end with a period (description-period)",
+			"12: info: --speed=<kn> should come before --version (option-order)",
+			"12: warning: --speed=<kn> takes a value but has no [default: ...] (missing-default)",
+			"13: error: malformed default \"[defualt: 10]\", want [default: <value>] (default-syntax)",
+			"15: info: --a-very-long-option-name-indeed=<value> should come before --speed=<kn> (option-order)",
+			"15: warning: --a-very-long-option-name-indeed=<value> is longer than 30 characters (option-length)",
+			"16: error: malformed default \"[default: [10]\", want [default: <value>] (default-syntax)",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("issues got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when rules are configured", func(t *testing.T) {
+		var config LintConfig
+		for _, setting := range []string{"option-order=off", "missing-default=error"} {
+			if err := config.ParseSetting(setting); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		rules := []LintRule{OptionOrderRule{}, MissingDefaultRule{}, &OptionLengthRule{Max: 50}}
+		issues := Lint(lintExampleFile, rules, &config)
+
+		if len(issues) != 1 {
+			t.Fatalf("number of issues got = %d, want 1: %v", len(issues), issues)
+		}
+
+		if issues[0].Rule != "missing-default" || issues[0].Severity != SeverityError {
+			t.Errorf("issue got = %v, want missing-default error", issues[0])
+		}
+	})
+
+	t.Run("when synopsis arguments are not documented", func(t *testing.T) {
+		lines := append(append([]string{}, lintExampleFile[:6]...), "## ARGUMENTS", "", "  * `<x>`:", "    X.", "")
+		issues := Lint(lines, []LintRule{SynopsisArgumentsRule{}}, nil)
+
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+
+		want := []string{
+			"7: error: argument <x> is documented but not used in SYNOPSIS (synopsis-arguments)",
+			"7: error: argument <name> is used in SYNOPSIS but not documented in ARGUMENTS (synopsis-arguments)",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("issues got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when hidden options are only used through [options]", func(t *testing.T) {
+		issues := Lint(annotatedPage, []LintRule{HiddenOptionRule{}}, nil)
+
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+
+		want := []string{
+			"11: warning: option --debug is hidden, but only used through [options], so it is listed in the usage anyway (hidden-option)",
+			"16: warning: option --trace is hidden, but only used through [options], so it is listed in the usage anyway (hidden-option)",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("issues got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when defaults differ in case", func(t *testing.T) {
+		lines := []string{
+			"## OPTIONS",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots. [Default: 10]",
+			"  * `--depth=<m>`:",
+			"    Depth in meters. [Defualt: 10]",
+		}
+		issues := Lint(lines, []LintRule{DefaultSyntaxRule{}}, nil)
+
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+
+		want := []string{
+			"5: error: malformed default \"[Defualt: 10]\", want [default: <value>] (default-syntax)",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("issues got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when defaults don't fit their option", func(t *testing.T) {
+		lines := []string{
+			"## OPTIONS",
+			"  * `--moored`:",
+			"    Moored mine. [default: true]",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots. [default: ]",
+		}
+		issues := Lint(lines, []LintRule{DefaultValueRule{}}, nil)
+
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+
+		want := []string{
+			"2: error: option --moored has a default, but takes no argument (default-value)",
+			"4: error: option --speed=<kn>: default is empty (default-value)",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("issues got = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestLintConfig_ParseSetting(t *testing.T) {
+	t.Run("when rule is unknown", func(t *testing.T) {
+		var config LintConfig
+		if err := config.ParseSetting("no-such-rule=off"); err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+
+	t.Run("when severity is unknown", func(t *testing.T) {
+		var config LintConfig
+		if err := config.ParseSetting("option-order=fatal"); err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+
+	t.Run("when setting has no severity", func(t *testing.T) {
+		var config LintConfig
+		if err := config.ParseSetting("option-order"); err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+}
+
+func TestLintIssue_JSON(t *testing.T) {
+	got, err := json.Marshal(LintIssue{Rule: "option-order", Severity: SeverityInfo, Line: 3, Message: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"rule":"option-order","severity":"info","line":3,"message":"m"}`
+	if string(got) != want {
+		t.Errorf("got = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ghostsquad/ronn2docopt"
+)
+
+var sectionHeaderRe = regexp.MustCompile(`^##\s+(.*)$`)
+var openDefaultRe = regexp.MustCompile(`\[default:\s*[^\]]*$`)
+
+// Diagnostics are the lint issues of the page, which include the diagnostics of the parser.
+// The config sets the section names of the page, the default config when nil, as for the other features.
+func Diagnostics(lines []string, config *ronn2docopt.Config) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, issue := range ronn2docopt.Lint(lines, nil, &ronn2docopt.LintConfig{Sections: config}) {
+		severity := SeverityInformation
+		switch issue.Severity {
+		case ronn2docopt.SeverityError:
+			severity = SeverityError
+		case ronn2docopt.SeverityWarning:
+			severity = SeverityWarning
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    lineRange(lines, issue.Line-1),
+			Severity: severity,
+			Code:     issue.Rule,
+			Source:   "ronn2docopt",
+			Message:  issue.Message,
+		})
+	}
+
+	return diagnostics
+}
+
+// HoverAt previews the docopt line generated for the option declared at the position, or nil
+func HoverAt(lines []string, position Position, config *ronn2docopt.Config) *Hover {
+	d := ronn2docopt.RonnToDocoptWithConfig(lines, config)
+
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			if o.Line-1 != position.Line {
+				continue
+			}
+
+			r := lineRange(lines, position.Line)
+
+			return &Hover{
+				Contents: MarkupContent{Kind: "markdown", Value: "```\n" + docoptLine(d, s, o) + "\n```"},
+				Range:    &r,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Symbols are the sections of the page, with the options of option sections as children
+func Symbols(lines []string, config *ronn2docopt.Config) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	d := ronn2docopt.RonnToDocoptWithConfig(lines, config)
+
+	var options []ronn2docopt.HelpOption
+	for _, s := range d.HelpOptionSections {
+		options = append(options, s.Options...)
+	}
+
+	for i, line := range lines {
+		m := sectionHeaderRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		end := len(lines) - 1
+		for j := i + 1; j < len(lines); j++ {
+			if sectionHeaderRe.MatchString(lines[j]) {
+				end = j - 1
+				break
+			}
+		}
+
+		section := DocumentSymbol{
+			Name:           strings.TrimSpace(m[1]),
+			Kind:           SymbolKindNamespace,
+			Range:          Range{Start: Position{Line: i}, End: Position{Line: end, Character: len(lines[end])}},
+			SelectionRange: lineRange(lines, i),
+		}
+
+		for _, o := range options {
+			if o.Line-1 > i && o.Line-1 <= end {
+				section.Children = append(section.Children, DocumentSymbol{
+					Name:           o.Name,
+					Detail:         ronn2docopt.PlainInline(o.Desc),
+					Kind:           SymbolKindProperty,
+					Range:          lineRange(lines, o.Line-1),
+					SelectionRange: lineRange(lines, o.Line-1),
+				})
+			}
+		}
+
+		symbols = append(symbols, section)
+	}
+
+	return symbols
+}
+
+// Completions are the options used in SYNOPSIS that are not documented yet, within an option section,
+// or the choices of the option, within its [default: ...]
+func Completions(lines []string, position Position, config *ronn2docopt.Config) []CompletionItem {
+	items := []CompletionItem{}
+
+	d := ronn2docopt.RonnToDocoptWithConfig(lines, config)
+	if !inSection(lines, position.Line, d.Config.OptionsSections) {
+		return items
+	}
+
+	if position.Line < len(lines) {
+		line := lines[position.Line]
+		if position.Character < len(line) {
+			line = line[:position.Character]
+		}
+
+		if openDefaultRe.MatchString(line) {
+			return choiceCompletions(d, position.Line)
+		}
+	}
+
+	documented := map[string]bool{}
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			for _, f := range strings.Fields(o.Name) {
+				documented[flagName(f)] = true
+			}
+		}
+	}
+
+	usages, err := ronn2docopt.ParseSynopsis(d.Synopsis)
+	if err != nil {
+		return items
+	}
+
+	seen := map[string]bool{}
+	for _, u := range usages {
+		for _, leaf := range u.Pattern.Leaves(ronn2docopt.OptionPattern) {
+			if documented[flagName(leaf.Name)] || seen[leaf.Name] {
+				continue
+			}
+			seen[leaf.Name] = true
+
+			items = append(items, CompletionItem{
+				Label:      leaf.Name,
+				Kind:       CompletionItemKindProperty,
+				Detail:     "used in SYNOPSIS",
+				InsertText: leaf.Name,
+			})
+		}
+	}
+
+	return items
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// The choices of the option described at the line
+func choiceCompletions(d *ronn2docopt.DocOpt, line int) []CompletionItem {
+	items := []CompletionItem{}
+
+	var option *ronn2docopt.HelpOption
+	for _, s := range d.HelpOptionSections {
+		for i := range s.Options {
+			if s.Options[i].Line-1 <= line {
+				option = &s.Options[i]
+			}
+		}
+	}
+
+	if option == nil {
+		return items
+	}
+
+	for _, c := range option.Choices {
+		items = append(items, CompletionItem{
+			Label:      c,
+			Kind:       CompletionItemKindEnumMember,
+			Detail:     "choice of " + option.Name,
+			InsertText: c,
+		})
+	}
+
+	return items
+}
+
+// The line of the option in the docopt usage, padded like the rest of its option section
+func docoptLine(d *ronn2docopt.DocOpt, section ronn2docopt.HelpOptionSection, option ronn2docopt.HelpOption) string {
+	single := ronn2docopt.DocOpt{Config: d.Config, HelpOptionSections: []ronn2docopt.HelpOptionSection{section}}
+
+	for _, line := range strings.Split(single.String(), "\n") {
+		if strings.HasPrefix(line, "  "+option.Name+" ") || line == "  "+option.Name {
+			return line
+		}
+	}
+
+	return "  " + option.Name
+}
+
+func inSection(lines []string, line int, names []string) bool {
+	for i := line; i >= 0 && i < len(lines); i-- {
+		if m := sectionHeaderRe.FindStringSubmatch(lines[i]); m != nil {
+			for _, n := range names {
+				if strings.EqualFold(strings.TrimSpace(m[1]), n) {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+
+	return false
+}
+
+// e.g. --speed=<kn> returns --speed
+func flagName(flag string) string {
+	if i := strings.IndexAny(flag, "=["); i != -1 {
+		return flag[:i]
+	}
+
+	return flag
+}
+
+func lineRange(lines []string, line int) Range {
+	length := 0
+	if line >= 0 && line < len(lines) {
+		length = len(lines[line])
+	}
+
+	return Range{Start: Position{Line: line}, End: Position{Line: line, Character: length}}
+}
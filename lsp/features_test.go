@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghostsquad/ronn2docopt"
+)
+
+var page = []string{
+	"naval_fate(6) -- the naval fate game",
+	"",
+	"## SYNOPSIS",
+	"",
+	"`naval_fate` `ship <name> move [--speed=<kn>] [--moored|--drifting]`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `-h`, `--help`:",
+	"    Show this screen.",
+	"  * `--speed=<kn>`:",
+	"    Speed in knots [default: 10]",
+	"",
+}
+
+func TestDiagnostics(t *testing.T) {
+	got := Diagnostics(page, nil)
+
+	var messages []string
+	for _, d := range got {
+		messages = append(messages, d.Code+": "+d.Message)
+	}
+
+	want := []string{"description-period: description of --speed=<kn> does not end with a period"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("diagnostics got = %q, want %q", messages, want)
+	}
+
+	wantRange := Range{Start: Position{Line: 10}, End: Position{Line: 10, Character: 19}}
+	if got[0].Range != wantRange || got[0].Severity != SeverityWarning {
+		t.Errorf("diagnostic got = %+v, want warning at %+v", got[0], wantRange)
+	}
+}
+
+func TestHoverAt(t *testing.T) {
+	t.Run("when on an option declaration", func(t *testing.T) {
+		got := HoverAt(page, Position{Line: 10, Character: 5}, nil)
+		if got == nil {
+			t.Fatal("hover got = nil")
+		}
+
+		want := "```\n  --speed=<kn>  Speed in knots [default: 10]\n```"
+		if got.Contents.Value != want {
+			t.Errorf("hover got = %q, want %q", got.Contents.Value, want)
+		}
+	})
+
+	t.Run("when not on an option declaration", func(t *testing.T) {
+		if got := HoverAt(page, Position{Line: 9}, nil); got != nil {
+			t.Errorf("hover got = %+v, want nil", got)
+		}
+	})
+
+	t.Run("when the sections are localized", func(t *testing.T) {
+		lines := []string{
+			"## SYNTAXE",
+			"",
+			"`naval_fate` `--version`",
+			"",
+			"## OPTIONEN",
+			"",
+			"  * `--version`:",
+			"    Version anzeigen.",
+		}
+		config := &ronn2docopt.Config{SynopsisSections: []string{"SYNTAXE"}, OptionsSections: []string{"OPTIONEN"}}
+
+		got := HoverAt(lines, Position{Line: 6, Character: 5}, config)
+		if got == nil {
+			t.Fatal("hover got = nil")
+		}
+
+		want := "```\n  --version  Version anzeigen.\n```"
+		if got.Contents.Value != want {
+			t.Errorf("hover got = %q, want %q", got.Contents.Value, want)
+		}
+	})
+}
+
+func TestSymbols(t *testing.T) {
+	got := Symbols(page, nil)
+
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Name)
+		for _, c := range s.Children {
+			names = append(names, "  "+c.Name)
+		}
+	}
+
+	want := []string{"SYNOPSIS", "OPTIONS", "  -h --help", "  --speed=<kn>"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("symbols got = %q, want %q", names, want)
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	t.Run("within OPTIONS", func(t *testing.T) {
+		var got []string
+		for _, item := range Completions(page, Position{Line: 12}, nil) {
+			got = append(got, item.Label)
+		}
+
+		want := []string{"--moored", "--drifting"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("completions got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("within a default of an option with choices", func(t *testing.T) {
+		lines := []string{
+			"## OPTIONS",
+			"  * `--format=<fmt>`:",
+			"    Output format. [choices: json, yaml] [default: ]",
+		}
+
+		var got []string
+		for _, item := range Completions(lines, Position{Line: 2, Character: 50}, nil) {
+			got = append(got, item.Label)
+		}
+
+		want := []string{"json", "yaml"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("completions got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("outside OPTIONS", func(t *testing.T) {
+		if got := Completions(page, Position{Line: 4}, nil); len(got) != 0 {
+			t.Errorf("completions got = %+v, want none", got)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+// Package lsp is a language server for ronn pages.
+// It publishes the lint issues and parser diagnostics of open pages, shows the docopt line of an option on hover,
+// lists sections and options as document symbols, and completes the options used in SYNOPSIS and the choices of defaults.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ghostsquad/ronn2docopt"
+)
+
+// A Server answers the requests read from in, and writes responses and notifications to out.
+// Requests are handled one at a time, in order.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// the section names of the pages
+	config *ronn2docopt.Config
+
+	mutex     sync.Mutex
+	documents map[string][]string
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return NewServerWithConfig(in, out, nil)
+}
+
+// NewServerWithConfig reads the pages with the section names of the config, see ronn2docopt.RonnToDocoptWithConfig
+func NewServerWithConfig(in io.Reader, out io.Writer, config *ronn2docopt.Config) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		config:    config,
+		documents: map[string][]string{},
+	}
+}
+
+// Serve handles requests until the client sends exit, or closes the connection
+func (s *Server) Serve() error {
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+
+		// notifications have no id, and get no response
+		if msg.ID == nil {
+			continue
+		}
+
+		response := &message{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rerr}
+		if rerr == nil && result == nil {
+			response.Result = json.RawMessage("null")
+		}
+
+		if err := s.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// full document sync
+				"textDocumentSync":       1,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"-", "`"},
+				},
+			},
+			"serverInfo": map[string]string{"name": "ronn2docopt"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		s.mutex.Lock()
+		delete(s.documents, params.TextDocument.URI)
+		s.mutex.Unlock()
+
+		s.publish(params.TextDocument.URI, []Diagnostic{})
+		return nil, nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		return HoverAt(s.lines(params.TextDocument.URI), params.Position, s.config), nil
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		return Symbols(s.lines(params.TextDocument.URI), s.config), nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: invalidParams, Message: err.Error()}
+		}
+
+		return Completions(s.lines(params.TextDocument.URI), params.Position, s.config), nil
+	}
+
+	if strings.HasPrefix(msg.Method, "$/") {
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: methodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func (s *Server) update(uri string, text string) {
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+
+	s.mutex.Lock()
+	s.documents[uri] = lines
+	s.mutex.Unlock()
+
+	s.publish(uri, Diagnostics(lines, s.config))
+}
+
+func (s *Server) lines(uri string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.documents[uri]
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) {
+	params, _ := json.Marshal(PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+
+	s.write(&message{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// Messages are framed by a Content-Length header
+func (s *Server) read() (*message, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return &message{}, s.write(&message{
+			JSONRPC: "2.0",
+			Error:   &responseError{Code: parseError, Message: err.Error()},
+		})
+	}
+
+	return &msg, nil
+}
+
+func (s *Server) write(msg *message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
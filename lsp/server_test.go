@@ -0,0 +1,97 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func request(id int, method string, params interface{}) string {
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func notification(method string, params interface{}) string {
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// Reads the framed messages written by the server
+func responses(t *testing.T, out []byte) []map[string]interface{} {
+	var messages []map[string]interface{}
+
+	r := bufio.NewReader(bytes.NewReader(out))
+	for {
+		var length int
+		if _, err := fmt.Fscanf(r, "Content-Length: %d\r\n\r\n", &length); err != nil {
+			break
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatal(err)
+		}
+
+		var m map[string]interface{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages
+}
+
+func TestServer(t *testing.T) {
+	uri := "file:///docs/naval_fate.1.ronn"
+	text := strings.Join(page, "\n")
+
+	in := request(1, "initialize", map[string]interface{}{}) +
+		notification("initialized", map[string]interface{}{}) +
+		notification("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": text},
+		}) +
+		request(2, "textDocument/hover", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     map[string]interface{}{"line": 8, "character": 4},
+		}) +
+		request(3, "unknown/method", nil) +
+		request(4, "shutdown", nil) +
+		notification("exit", nil)
+
+	var out bytes.Buffer
+	if err := NewServer(bufio.NewReader(strings.NewReader(in)), &out).Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := responses(t, out.Bytes())
+	if len(got) != 5 {
+		t.Fatalf("number of messages got = %d, want 5: %v", len(got), got)
+	}
+
+	capabilities := got[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if capabilities["hoverProvider"] != true {
+		t.Errorf("capabilities got = %v, want hoverProvider", capabilities)
+	}
+
+	if got[1]["method"] != "textDocument/publishDiagnostics" {
+		t.Errorf("message got = %v, want diagnostics", got[1])
+	}
+
+	hover := got[2]["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"]
+	if hover != "```\n  -h --help     Show this screen.\n```" {
+		t.Errorf("hover got = %q", hover)
+	}
+
+	if code := got[3]["error"].(map[string]interface{})["code"]; code != float64(methodNotFound) {
+		t.Errorf("error code got = %v, want %d", code, methodNotFound)
+	}
+
+	if _, ok := got[4]["result"]; !ok || got[4]["result"] != nil {
+		t.Errorf("shutdown got = %v, want null result", got[4])
+	}
+}
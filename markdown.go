@@ -0,0 +1,155 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// The generated markdown goes between these markers, the rest of the file is left untouched
+const MarkdownBeginMarker = "<!-- ronn2docopt:begin -->"
+const MarkdownEndMarker = "<!-- ronn2docopt:end -->"
+
+// Markdown renders a GitHub flavored markdown usage block, followed by an options table per option section
+func (d *DocOpt) Markdown() string {
+	var buffer bytes.Buffer
+
+	config := d.Config.withDefaults()
+
+	buffer.WriteString("```\n")
+	buffer.WriteString(config.UsageHeading + "\n")
+	buffer.WriteString(d.Synopsis + "\n")
+	buffer.WriteString("```\n")
+
+	for i, s := range d.HelpOptionSections {
+		if !s.hasVisibleOptions() {
+			continue
+		}
+
+		buffer.WriteString("\n")
+
+		if i > 0 && s.Name != "" {
+			buffer.WriteString(s.Name + "\n")
+			buffer.WriteString("\n")
+		}
+
+		buffer.WriteString("| Option | Description | Default |\n")
+		buffer.WriteString("| --- | --- | --- |\n")
+
+		for _, o := range s.Options {
+			if o.Hidden {
+				continue
+			}
+
+			var flags []string
+			for _, f := range splitOptionName(o.Name) {
+				flags = append(flags, "`"+f+"`")
+			}
+
+			def := ""
+			if o.DefaultValue != "" {
+				def = "`" + defaultValueText(o.DefaultValue) + "`"
+			}
+
+			desc := o.Desc
+			if o.Deprecated && o.Deprecation != "" {
+				desc += " **Deprecated**: " + o.Deprecation + "."
+			} else if o.Deprecated {
+				desc += " **Deprecated**."
+			}
+
+			if len(o.Choices) > 0 {
+				desc += " Choices: `" + strings.Join(o.Choices, "`, `") + "`."
+			}
+
+			buffer.WriteString("| " + markdownCell(strings.Join(flags, ", ")) +
+				" | " + markdownCell(desc) +
+				" | " + markdownCell(def) + " |\n")
+		}
+	}
+
+	return buffer.String()
+}
+
+// UpdateMarkdownSection replaces the content between the markers with the given markdown
+func UpdateMarkdownSection(content string, markdown string) (string, error) {
+	begin := strings.Index(content, MarkdownBeginMarker)
+	if begin == -1 {
+		return "", fmt.Errorf("marker %s not found", MarkdownBeginMarker)
+	}
+
+	end := strings.Index(content[begin:], MarkdownEndMarker)
+	if end == -1 {
+		return "", fmt.Errorf("marker %s not found after %s", MarkdownEndMarker, MarkdownBeginMarker)
+	}
+	end += begin
+
+	return content[:begin+len(MarkdownBeginMarker)] + "\n" +
+		strings.TrimRight(markdown, "\n") + "\n" +
+		content[end:], nil
+}
+
+// UpdateMarkdownFile replaces the content between the markers of the markdown file,
+// returning whether the content changed. In check mode, the file is never written.
+func UpdateMarkdownFile(markdownFile string, markdown string, check bool) (bool, error) {
+	content, err := ioutil.ReadFile(markdownFile)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := UpdateMarkdownSection(string(content), markdown)
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", markdownFile, err)
+	}
+
+	if updated == string(content) {
+		return false, nil
+	}
+
+	if check {
+		return true, nil
+	}
+
+	info, err := os.Stat(markdownFile)
+	if err != nil {
+		return true, err
+	}
+
+	return true, ioutil.WriteFile(markdownFile, []byte(updated), info.Mode())
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// Splits an option name into its flags, keeping each flag's argument
+// e.g. "-s <kn> --speed=<kn>" returns [-s <kn>, --speed=<kn>]
+func splitOptionName(name string) []string {
+	var flags []string
+
+	for _, part := range strings.Fields(strings.Replace(name, ",", " ", -1)) {
+		if strings.HasPrefix(part, "-") || len(flags) == 0 {
+			flags = append(flags, part)
+			continue
+		}
+
+		flags[len(flags)-1] += " " + part
+	}
+
+	return flags
+}
+
+// e.g. "[default: 10]" returns 10
+func defaultValueText(defaultValue string) string {
+	s := strings.TrimPrefix(defaultValue, "[default:")
+	s = strings.TrimSuffix(s, "]")
+
+	return strings.TrimSpace(s)
+}
+
+// Pipes would end the table cell
+func markdownCell(s string) string {
+	return strings.Replace(s, "|", "\\|", -1)
+}
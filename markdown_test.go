@@ -0,0 +1,165 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func TestDocOpt_Markdown(t *testing.T) {
+	d := RonnToDocopt(exampleFile)
+	got := d.Markdown()
+
+	want := "```\n" +
+		"Usage:\n" +
+		"  naval_fate ship new <name>...\n" +
+		"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+		"  naval_fate ship shoot <x> <y>\n" +
+		"  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]\n" +
+		"  naval_fate -h | --help\n" +
+		"  naval_fate --version\n" +
+		"```\n" +
+		"\n" +
+		"| Option | Description | Default |\n" +
+		"| --- | --- | --- |\n" +
+		"| `-h`, `--help` | Show this screen. |  |\n" +
+		"| `--version` | Show version. |  |\n" +
+		"| `--speed=<kn>` | Speed in knots. | `10` |\n" +
+		"| `--foo` | Multiline description |  |\n" +
+		"\n" +
+		"Other Options\n" +
+		"\n" +
+		"| Option | Description | Default |\n" +
+		"| --- | --- | --- |\n" +
+		"| `-b` | Thingy | `baz` |\n"
+
+	if got != want {
+		diff := difflib.UnifiedDiff{
+			A:       difflib.SplitLines(want),
+			B:       difflib.SplitLines(got),
+			Context: 3,
+		}
+		text, _ := difflib.GetUnifiedDiffString(diff)
+
+		fmt.Println(text)
+		t.Error()
+	}
+}
+
+func TestDocOpt_Markdown_Annotations(t *testing.T) {
+	got := RonnToDocopt(annotatedPage).Markdown()
+
+	want := "```\n" +
+		"Usage:\n" +
+		"  thing [options] <file>\n" +
+		"```\n" +
+		"\n" +
+		"| Option | Description | Default |\n" +
+		"| --- | --- | --- |\n" +
+		"| `--speed=<kn>` | Speed in knots. |  |\n" +
+		"| `--velocity=<kn>` | Speed in knots. **Deprecated**: use --speed. |  |\n"
+
+	if got != want {
+		t.Errorf("got = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateMarkdownSection(t *testing.T) {
+	t.Run("replaces content between markers", func(t *testing.T) {
+		content := "# Naval Fate\n" +
+			"<!-- ronn2docopt:begin -->\n" +
+			"old usage\n" +
+			"<!-- ronn2docopt:end -->\n" +
+			"## License\n"
+
+		got, err := UpdateMarkdownSection(content, "new usage\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "# Naval Fate\n" +
+			"<!-- ronn2docopt:begin -->\n" +
+			"new usage\n" +
+			"<!-- ronn2docopt:end -->\n" +
+			"## License\n"
+
+		if got != want {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when begin marker is missing", func(t *testing.T) {
+		_, err := UpdateMarkdownSection("<!-- ronn2docopt:end -->", "usage")
+		if err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+
+	t.Run("when end marker is before begin marker", func(t *testing.T) {
+		_, err := UpdateMarkdownSection("<!-- ronn2docopt:end -->\n<!-- ronn2docopt:begin -->", "usage")
+		if err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+}
+
+func TestUpdateMarkdownFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ronn2docopt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "README.md")
+	content := "<!-- ronn2docopt:begin -->\nold\n<!-- ronn2docopt:end -->\n"
+
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("check mode does not write", func(t *testing.T) {
+		changed, err := UpdateMarkdownFile(file, "new", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !changed {
+			t.Error("changed got = false, want true")
+		}
+
+		got, _ := ioutil.ReadFile(file)
+		if string(got) != content {
+			t.Errorf("content got = %q, want %q", got, content)
+		}
+	})
+
+	t.Run("writes in place", func(t *testing.T) {
+		changed, err := UpdateMarkdownFile(file, "new", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !changed {
+			t.Error("changed got = false, want true")
+		}
+
+		changed, _ = UpdateMarkdownFile(file, "new", true)
+		if changed {
+			t.Error("changed after update got = true, want false")
+		}
+	})
+}
+
+func TestSplitOptionName(t *testing.T) {
+	got := splitOptionName("-s <kn>, --speed=<kn>")
+	want := []string{"-s <kn>", "--speed=<kn>"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+}
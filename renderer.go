@@ -0,0 +1,179 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A ParsedPage is a ronn page, with its docopt usage and man page document parsed, ready to be rendered
+type ParsedPage struct {
+	Lines    []string
+	DocOpt   *DocOpt
+	Document *Document
+}
+
+// RenderOptions are the options of every format, renderers use the ones that apply to them
+type RenderOptions struct {
+	Roff RoffOptions
+	HTML HTMLOptions
+}
+
+// A Renderer writes a parsed ronn page in an output format
+type Renderer interface {
+	Render(page *ParsedPage, options RenderOptions) ([]byte, error)
+}
+
+// RendererFunc adapts a function to a Renderer
+type RendererFunc func(page *ParsedPage, options RenderOptions) ([]byte, error)
+
+func (f RendererFunc) Render(page *ParsedPage, options RenderOptions) ([]byte, error) {
+	return f(page, options)
+}
+
+var renderersMu sync.RWMutex
+var renderers = map[string]Renderer{}
+
+func init() {
+	RegisterRenderer("docopt", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		return []byte(page.DocOpt.String() + "\n"), nil
+	}))
+
+	RegisterRenderer("ansi", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		return []byte(page.DocOpt.ANSI() + "\n"), nil
+	}))
+
+	RegisterRenderer("json", RendererFunc(renderJSON))
+
+	RegisterRenderer("markdown", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		return []byte(page.DocOpt.Markdown()), nil
+	}))
+
+	RegisterRenderer("roff", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		return []byte(page.Document.Roff(options.Roff)), nil
+	}))
+
+	RegisterRenderer("html", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		return []byte(page.Document.HTML(options.HTML)), nil
+	}))
+
+	RegisterRenderer("argparse", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		s, err := page.DocOpt.Argparse()
+		return []byte(s), err
+	}))
+
+	RegisterRenderer("clap", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+		s, err := page.DocOpt.Clap()
+		return []byte(s), err
+	}))
+}
+
+// ParsePage parses the docopt usage and the man page document of a ronn page,
+// reading the sections of the config, or of the default config when it's nil
+func ParsePage(lines []string, config *Config) *ParsedPage {
+	return &ParsedPage{
+		Lines:    lines,
+		DocOpt:   RonnToDocoptWithConfig(lines, config),
+		Document: ParseDocument(lines),
+	}
+}
+
+// RegisterRenderer makes a format available by name, e.g. from the init function of another package.
+// It panics when the name is empty, or already registered.
+func RegisterRenderer(name string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	if name == "" || r == nil {
+		panic("ronn2docopt: RegisterRenderer needs a name and a renderer")
+	}
+
+	if _, ok := renderers[name]; ok {
+		panic(fmt.Sprintf("ronn2docopt: RegisterRenderer called twice for %q", name))
+	}
+
+	renderers[name] = r
+}
+
+// LookupRenderer returns the renderer of a format, or nil when it's not registered
+func LookupRenderer(name string) Renderer {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	return renderers[name]
+}
+
+// RendererNames returns the registered formats, sorted
+func RendererNames() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// FindRenderer returns the renderer of a format, or an error listing the registered formats
+func FindRenderer(name string) (Renderer, error) {
+	r := LookupRenderer(name)
+	if r == nil {
+		return nil, fmt.Errorf("unknown format %q, want one of %s", name, strings.Join(RendererNames(), ", "))
+	}
+
+	return r, nil
+}
+
+// Render writes the page in the named format
+func Render(format string, page *ParsedPage, options RenderOptions) ([]byte, error) {
+	r, err := FindRenderer(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Render(page, options)
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// The json format is the docopt model, i.e. the synopsis, arguments, commands, options, environment and diagnostics.
+// Missing sections are empty lists, rather than null.
+func renderJSON(page *ParsedPage, options RenderOptions) ([]byte, error) {
+	d := *page.DocOpt
+
+	if d.Arguments == nil {
+		d.Arguments = []HelpArgument{}
+	}
+	if d.Commands == nil {
+		d.Commands = []HelpCommand{}
+	}
+	if d.HelpOptionSections == nil {
+		d.HelpOptionSections = []HelpOptionSection{}
+	}
+	if d.Environment == nil {
+		d.Environment = []HelpEnvironment{}
+	}
+	if d.Diagnostics == nil {
+		d.Diagnostics = []Diagnostic{}
+	}
+
+	var buffer bytes.Buffer
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(&d); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func TestRender(t *testing.T) {
+	page := ParsePage(annotatedPage, nil)
+
+	t.Run("registers the built in formats", func(t *testing.T) {
+		got := RendererNames()
+		want := []string{"ansi", "argparse", "clap", "docopt", "html", "json", "markdown", "roff"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("RendererNames() got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("renders the docopt usage", func(t *testing.T) {
+		got, err := Render("docopt", page, RenderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := page.DocOpt.String() + "\n"; string(got) != want {
+			t.Errorf("Render() got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("renders the model as json", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`thing` `[-v] [--speed=<kn>]`",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `-v`:",
+			"    Be verbose. [repeatable]",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots. [default: 10]",
+			"",
+		}
+
+		got, err := Render("json", ParsePage(lines, nil), RenderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `{
+  "synopsis": "  thing [-v...] [--speed=<kn>]",
+  "arguments": [],
+  "commands": [],
+  "optionSections": [
+    {
+      "name": "",
+      "options": [
+        {
+          "name": "-v",
+          "desc": "Be verbose.",
+          "default": null,
+          "cardinality": "counted",
+          "line": 7
+        },
+        {
+          "name": "--speed=<kn>",
+          "desc": "Speed in knots.",
+          "default": {
+            "kind": "int",
+            "text": "10",
+            "value": 10
+          },
+          "cardinality": "single",
+          "line": 9
+        }
+      ]
+    }
+  ],
+  "environment": [],
+  "diagnostics": []
+}
+`
+
+		if string(got) != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(string(got)),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when a format is registered", func(t *testing.T) {
+		RegisterRenderer("test-synopsis", RendererFunc(func(page *ParsedPage, options RenderOptions) ([]byte, error) {
+			return []byte(strings.TrimSpace(page.DocOpt.Synopsis)), nil
+		}))
+		defer func() {
+			renderersMu.Lock()
+			delete(renderers, "test-synopsis")
+			renderersMu.Unlock()
+		}()
+
+		got, err := Render("test-synopsis", page, RenderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := "thing [options] <file>"; string(got) != want {
+			t.Errorf("Render() got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when a format is registered twice", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterRenderer() didn't panic")
+			}
+		}()
+
+		RegisterRenderer("docopt", LookupRenderer("docopt"))
+	})
+
+	t.Run("when the format is unknown", func(t *testing.T) {
+		_, err := Render("nope", page, RenderOptions{})
+
+		want := `unknown format "nope", want one of ansi, argparse, clap, docopt, html, json, markdown, roff`
+		if err == nil || err.Error() != want {
+			t.Errorf("err got = %v, want %s", err, want)
+		}
+	})
+}
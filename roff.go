@@ -0,0 +1,170 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"strings"
+)
+
+// RoffOptions are the document attributes displayed in the header and footer of the man page
+type RoffOptions struct {
+	Date         string
+	Manual       string
+	Organization string
+}
+
+// Roff renders the document as a man(7) page
+func (doc *Document) Roff(options RoffOptions) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(".\\\" generated with ronn2docopt\n")
+	buffer.WriteString(".\\\" https://github.com/ghostsquad/ronn2docopt\n")
+	buffer.WriteString(".\n")
+
+	buffer.WriteString(".TH " + roffQuote(strings.ToUpper(doc.Name)) + " " + roffQuote(doc.ManSection) + " " +
+		roffQuote(options.Date) + " " + roffQuote(options.Organization) + " " + roffQuote(options.Manual) + "\n")
+
+	if doc.Name != "" {
+		buffer.WriteString(".SH \"NAME\"\n")
+		buffer.WriteString("\\fB" + roffEscape(doc.Name) + "\\fR")
+		if doc.Tagline != "" {
+			buffer.WriteString(" \\- " + roffInline(doc.Tagline))
+		}
+		buffer.WriteString("\n")
+	}
+
+	for _, s := range doc.Sections {
+		buffer.WriteString(".SH " + roffQuote(s.Name) + "\n")
+
+		for i, b := range s.Blocks {
+			writeRoffBlock(&buffer, b, i == 0)
+		}
+	}
+
+	return buffer.String()
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func writeRoffBlock(buffer *bytes.Buffer, b Block, first bool) {
+	switch b.Kind {
+	case ParagraphBlock:
+		if !first {
+			buffer.WriteString(".P\n")
+		}
+		writeRoffText(buffer, b.Lines)
+	case SubheadingBlock:
+		buffer.WriteString(".SS " + roffQuote(b.Lines[0]) + "\n")
+	case CodeBlock:
+		buffer.WriteString(".IP \"\" 4\n")
+		buffer.WriteString(".nf\n")
+		for _, line := range b.Lines {
+			buffer.WriteString(roffLine(roffEscape(line)) + "\n")
+		}
+		buffer.WriteString(".fi\n")
+		buffer.WriteString(".IP \"\" 0\n")
+	case DefinitionListBlock:
+		for _, item := range b.Items {
+			buffer.WriteString(".TP\n")
+			buffer.WriteString(roffLine(roffInline(item.Term)) + "\n")
+			writeRoffParagraphs(buffer, item.Lines)
+		}
+	case ListBlock:
+		for _, item := range b.Items {
+			buffer.WriteString(".IP \"\\(bu\" 4\n")
+			writeRoffParagraphs(buffer, item.Lines)
+		}
+		buffer.WriteString(".IP \"\" 0\n")
+	}
+}
+
+// Writes paragraphs separated by blank lines, as indented paragraphs
+func writeRoffParagraphs(buffer *bytes.Buffer, lines []string) {
+	var paragraph []string
+
+	first := true
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+
+		if !first {
+			buffer.WriteString(".IP \"\" 4\n")
+		}
+		writeRoffText(buffer, paragraph)
+
+		paragraph = nil
+		first = false
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+
+	flush()
+}
+
+// Writes filled text, turning trailing <br> into line breaks
+func writeRoffText(buffer *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		br := false
+		if m := brRe.FindStringSubmatch(line); m != nil {
+			line = strings.TrimSpace(m[1])
+			br = true
+		}
+
+		buffer.WriteString(roffLine(roffInline(line)) + "\n")
+
+		if br {
+			buffer.WriteString(".br\n")
+		}
+	}
+}
+
+// Escapes characters that have a special meaning to roff
+func roffEscape(s string) string {
+	s = strings.Replace(s, "\\", "\\e", -1)
+	s = strings.Replace(s, "-", "\\-", -1)
+
+	return s
+}
+
+// Converts ronn inline markup to roff font escapes:
+// `code` and **strong** are bold, _emphasis_ and <variables> are italic
+func roffInline(s string) string {
+	var buffer bytes.Buffer
+
+	for _, in := range ParseInline(s) {
+		switch in.Kind {
+		case CodeInline:
+			buffer.WriteString(`\fB` + codeVariableRe.ReplaceAllString(roffEscape(in.Text), `\fI$1\fR`) + `\fR`)
+		case StrongInline:
+			buffer.WriteString(`\fB` + roffEscape(in.Text) + `\fR`)
+		case EmphasisInline, VariableInline:
+			buffer.WriteString(`\fI` + roffEscape(in.Text) + `\fR`)
+		default:
+			buffer.WriteString(roffEscape(in.Text))
+		}
+	}
+
+	return buffer.String()
+}
+
+// Lines starting with a period or apostrophe would be read as requests
+func roffLine(s string) string {
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		return "\\&" + s
+	}
+
+	return s
+}
+
+func roffQuote(s string) string {
+	return "\"" + strings.Replace(s, "\"", "\\(dq", -1) + "\""
+}
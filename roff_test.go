@@ -0,0 +1,97 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func TestDocument_Roff(t *testing.T) {
+	t.Run("end 2 end", func(t *testing.T) {
+		lines := []string{
+			"naval_fate(6) -- the naval fate game",
+			"=====================================",
+			"",
+			"## SYNOPSIS",
+			"",
+			"`naval_fate` `ship new <name>...`<br>",
+			"`naval_fate` `--version`",
+			"",
+			"## DESCRIPTION",
+			"",
+			"**Naval Fate** is a _game_.",
+			".dot at the start.",
+			"",
+			"    $ naval_fate --version",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `--speed=<kn>`:",
+			"    Speed in knots.",
+			"",
+			"    Second paragraph.",
+			"",
+		}
+
+		got := ParseDocument(lines).Roff(RoffOptions{Date: "October 2026", Manual: "Naval Fate Manual"})
+
+		want := ".\\\" generated with ronn2docopt\n" +
+			".\\\" https://github.com/ghostsquad/ronn2docopt\n" +
+			".\n" +
+			".TH \"NAVAL_FATE\" \"6\" \"October 2026\" \"\" \"Naval Fate Manual\"\n" +
+			".SH \"NAME\"\n" +
+			"\\fBnaval_fate\\fR \\- the naval fate game\n" +
+			".SH \"SYNOPSIS\"\n" +
+			"\\fBnaval_fate\\fR \\fBship new \\fIname\\fR...\\fR\n" +
+			".br\n" +
+			"\\fBnaval_fate\\fR \\fB\\-\\-version\\fR\n" +
+			".SH \"DESCRIPTION\"\n" +
+			"\\fBNaval Fate\\fR is a \\fIgame\\fR.\n" +
+			"\\&.dot at the start.\n" +
+			".IP \"\" 4\n" +
+			".nf\n" +
+			"$ naval_fate \\-\\-version\n" +
+			".fi\n" +
+			".IP \"\" 0\n" +
+			".SH \"OPTIONS\"\n" +
+			".TP\n" +
+			"\\fB\\-\\-speed=\\fIkn\\fR\\fR\n" +
+			"Speed in knots.\n" +
+			".IP \"\" 4\n" +
+			"Second paragraph.\n"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+}
+
+func TestRoffInline(t *testing.T) {
+	tests := map[string]string{
+		"plain text":                "plain text",
+		"`code`":                    "\\fBcode\\fR",
+		"**strong**":                "\\fBstrong\\fR",
+		"_emphasis_":                "\\fIemphasis\\fR",
+		"snake_case_name":           "snake_case_name",
+		"<file>":                    "\\fIfile\\fR",
+		"&lt;literal&gt;":           "<literal>",
+		"see [docopt](http://x.io)": "see docopt",
+		"back\\slash":               "back\\eslash",
+	}
+
+	for in, want := range tests {
+		got := roffInline(in)
+		if got != want {
+			t.Errorf("roffInline(%q) got = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,16 +1,21 @@
 package ronn2docopt
 
 import (
+	"fmt"
 	"strings"
 	"bytes"
 	"regexp"
 	"os"
-	"bufio"
 )
 
 type DocOpt struct {
-	Synopsis string
-	HelpOptionSections []HelpOptionSection
+	Synopsis string `json:"synopsis"`
+	Arguments []HelpArgument `json:"arguments"`
+	Commands []HelpCommand `json:"commands"`
+	HelpOptionSections []HelpOptionSection `json:"optionSections"`
+	Environment []HelpEnvironment `json:"environment"`
+	Config *Config `json:"-"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
 type Synopsis struct {
@@ -18,22 +23,60 @@ type Synopsis struct {
 }
 
 type HelpOptionSection struct {
-	Name string
-	Options []HelpOption
+	Name string `json:"name"`
+	Options []HelpOption `json:"options"`
+}
+
+type HelpArgument struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+type HelpCommand struct {
+	Name    string       `json:"name"`
+	Desc    string       `json:"desc"`
+	Usage   []string     `json:"usage,omitempty"`
+	Options []HelpOption `json:"options,omitempty"`
 }
 
 type HelpOption struct {
-	Name         string
-	Desc         string
-	DefaultValue string
+	Name         string      `json:"name"`
+	Desc         string      `json:"desc"`
+	DefaultValue string      `json:"-"`
+	Default      Default     `json:"default"`
+	Choices      []string    `json:"choices,omitempty"`
+	Deprecated   bool        `json:"deprecated,omitempty"`
+	Deprecation  string      `json:"deprecation,omitempty"`
+	Hidden       bool        `json:"hidden,omitempty"`
+	Cardinality  Cardinality `json:"cardinality"`
+	Env          string      `json:"env,omitempty"`
+	Line         int         `json:"line"`
+}
+
+type HelpEnvironment struct {
+	Name   string `json:"name"`
+	Desc   string `json:"desc"`
+	Option string `json:"option,omitempty"`
 }
 
 var brRe = regexp.MustCompile(`^(.*)\s*(<br>)\s*$`)
 
 var sectionHeaderRe, sectionHeaderMa = RegexAndMatchNames(`^##\s+(?P<section>.*)$`)
-var namedOptionRe, namedOptionMa = RegexAndMatchNames(`^ {2}\* ` + "`?" + `(?P<name>-.*):$`)
-var defaultValueRe, defaultValueMa = RegexAndMatchNames(`^ {4}(?P<before>.*)(?P<default>\[default: .*\]$)`)
-var shortOptionDescRe, shortOptionDescMa = RegexAndMatchNames(`^ {4}(?P<short>.*?[.!?]).*$`)
+// declarations are bullets (*, - or +) at any indentation
+const bulletPattern = `^(?P<indent> *)[*+-] +`
+
+// markdown tab stops
+const tabWidth = 4
+
+var namedOptionRe, namedOptionMa = RegexAndMatchNames(bulletPattern + "(?P<name>`?-.*):$")
+var defaultValueRe, defaultValueMa = RegexAndMatchNames(`^\s+(?P<before>.*)(?P<default>\[default: .*\]$)`)
+// [choices: a, b], [deprecated: use --speed], [deprecated], [hidden] and [repeatable]
+var annotationRe = regexp.MustCompile(`\s*\[(choices|deprecated|hidden|repeatable)(?::\s*([^\[\]]*))?\]`)
+var namedArgumentRe, namedArgumentMa = RegexAndMatchNames(bulletPattern + "(?P<name>`?<.*):$")
+var namedCommandRe, namedCommandMa = RegexAndMatchNames(bulletPattern + "`?" + `(?P<name>\w[\w-]*)` + "`?" + `:$`)
+var namedEnvironmentRe, namedEnvironmentMa = RegexAndMatchNames(bulletPattern + "`?" + `(?P<name>[A-Z_][A-Z0-9_]*)` + "`?" + `:$`)
+var overridesOptionRe, overridesOptionMa = RegexAndMatchNames(`(?i)\boverrides\s+` + "`?" + `(?P<option>-{1,2}[\w-]+)`)
+var synopsisArgumentRe = regexp.MustCompile(`(^|[^=\w])(<[^<>\s]+>)`)
 
 /*
 
@@ -54,34 +97,116 @@ var shortOptionDescRe, shortOptionDescMa = RegexAndMatchNames(`^ {4}(?P<short>.*
 
  */
 
+// String renders the docopt usage string, with the inline markup of descriptions removed
 func (d *DocOpt) String() string {
+	return d.render(PlainInline)
+}
+
+// ANSI renders the docopt usage string for a terminal, with the inline markup of descriptions styled
+func (d *DocOpt) ANSI() string {
+	return d.render(ANSIInline)
+}
+
+func (d *DocOpt) render(inline func(string) string) string {
 	var buffer bytes.Buffer
 
-	buffer.WriteString("Usage:\n")
+	config := d.Config.withDefaults()
+
+	buffer.WriteString(config.UsageHeading + "\n")
 
 	buffer.WriteString(d.Synopsis)
 	buffer.WriteString("\n\n")
 
-	buffer.WriteString("Options:\n")
+	if len(d.Arguments) > 0 {
+		buffer.WriteString(config.ArgumentsHeading + "\n")
+
+		longestArgumentNameLen := d.longestArgumentNameLen()
+
+		for _, a := range d.Arguments {
+			padding := 0
+			if len(a.Desc) > 0 {
+				// 2 spaces + name + 2 spaces
+				padding = longestArgumentNameLen + 4
+			}
+
+			buffer.WriteString(PadRight("  " + a.Name, " ", padding))
+			buffer.WriteString(inline(a.Desc))
+			buffer.WriteString("\n")
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	if len(d.Commands) > 0 {
+		buffer.WriteString(config.CommandsHeading + "\n")
+
+		longestCommandNameLen := d.longestCommandNameLen()
+
+		for _, c := range d.Commands {
+			padding := 0
+			if len(c.Desc) > 0 {
+				// 2 spaces + name + 2 spaces
+				padding = longestCommandNameLen + 4
+			}
+
+			buffer.WriteString(PadRight("  " + c.Name, " ", padding))
+			buffer.WriteString(inline(c.Desc))
+			buffer.WriteString("\n")
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	buffer.WriteString(config.OptionsHeading + "\n")
+
+	unlisted, _ := d.unlistedOptions()
 
 	for i, s := range d.HelpOptionSections {
+		if len(s.Options) > 0 && !s.hasListedOptions(unlisted) {
+			continue
+		}
+
 		if i > 0 && s.Name != "" {
-			buffer.WriteString(s.Name)
+			buffer.WriteString(inline(s.Name))
 			buffer.WriteString("\n")
 		}
 
-		longestOptionNameLen := s.longestOptionNameLen()
+		longestOptionNameLen := s.longestOptionNameLen(unlisted)
 
 		for _, o := range s.Options {
+			// hidden options are only documented in the man page
+			if unlisted[o.Name] {
+				continue
+			}
+
 			padding := 0
-			if len(o.Desc) > 0 || len(o.DefaultValue) > 0 {
+			if len(o.Desc) > 0 || len(o.DefaultValue) > 0 || len(o.Env) > 0 || len(o.Choices) > 0 || o.Deprecated {
 				// 2 spaces + name + 2 spaces
 				padding = longestOptionNameLen + 4
 			}
 
 			on := PadRight("  " + o.Name, " ", padding)
 			buffer.WriteString(on)
-			buffer.WriteString(o.Desc)
+			buffer.WriteString(inline(o.Desc))
+
+			if o.Deprecated {
+				buffer.WriteString(" ")
+				buffer.WriteString(o.deprecationNote())
+			}
+
+			// docopt reads everything up to the last ] as the default,
+			// so the env binding and choices have to come before it
+			if len(o.Env) > 0 {
+				buffer.WriteString(" [env: ")
+				buffer.WriteString(o.Env)
+				buffer.WriteString("]")
+			}
+
+			if len(o.Choices) > 0 {
+				buffer.WriteString(" [choices: ")
+				buffer.WriteString(strings.Join(o.Choices, ", "))
+				buffer.WriteString("]")
+			}
 
 			if len(o.DefaultValue) > 0 {
 				buffer.WriteString(" ")
@@ -101,21 +226,46 @@ func (d *DocOpt) String() string {
 }
 
 func  RonnToDocopt(lines []string) *DocOpt {
+	return RonnToDocoptWithConfig(lines, DefaultConfig())
+}
+
+// RonnToDocoptWithConfig converts using the section names and headings of the given config.
+// Empty config fields fall back to the default config.
+func RonnToDocoptWithConfig(lines []string, config *Config) *DocOpt {
 	var d DocOpt
 
-	s := getSection(lines, "SYNOPSIS")
+	config = config.withDefaults()
+	d.Config = config
+
+	lines = normalizeLines(lines)
+
+	// indentation is checked before tabs are expanded, to catch mixed tabs and spaces
+	raw, rawStart := getFirstSectionAt(lines, config.OptionsSections...)
+	d.Diagnostics = append(d.Diagnostics, optionIndentationDiagnostics(raw, rawStart + 1)...)
+
+	lines = expandTabLines(lines)
+
+	s := getFirstSection(lines, config.SynopsisSections...)
 	d.Synopsis = formatSynopsis(s)
 
-	o := getSection(lines, "OPTIONS")
+	a := getFirstSection(lines, config.ArgumentsSections...)
+	d.Arguments = newArguments(a)
+
+	c := getFirstSection(lines, config.CommandsSections...)
+	d.Commands = newCommands(c)
+
+	o, oStart := getFirstSectionAt(lines, config.OptionsSections...)
 
 	lastWasOptions := false
 	var sectionLines []string
+	sectionStart := oStart
 
-	for _, line := range o {
+	for i, line := range o {
 		if lastWasOptions && isSectionDescriptionLine(line) {
-			s := newOptionSection(sectionLines)
+			s := newOptionSection(sectionLines, sectionStart + 1)
 			d.HelpOptionSections = append(d.HelpOptionSections, *s)
 			sectionLines = nil
+			sectionStart = oStart + i
 			lastWasOptions = false
 		} else if f, _ := isOptionDeclaration(line); f {
 			lastWasOptions = true
@@ -126,24 +276,115 @@ func  RonnToDocopt(lines []string) *DocOpt {
 
 	// append final section
 	if len(sectionLines) > 0 {
-		s := newOptionSection(sectionLines)
+		s := newOptionSection(sectionLines, sectionStart + 1)
 		d.HelpOptionSections = append(d.HelpOptionSections, *s)
 	}
 
+	d.Diagnostics = append(d.Diagnostics, d.typeDefaults()...)
+	d.Diagnostics = append(d.Diagnostics, d.linkCardinality()...)
+	d.Diagnostics = append(d.Diagnostics, d.hiddenOptionDiagnostics()...)
+
+	e := getFirstSection(lines, config.EnvironmentSections...)
+	d.Environment = newEnvironment(e)
+
+	d.linkCommands()
+	d.linkEnvironment()
+
 	return &d
 }
 
-func ConvertRonnFile(ronnFile string) (string, error) {
-	file, err := os.Open(ronnFile)
-	defer file.Close()
+// Command returns the help for a single subcommand, derived from the synopsis tree.
+// Only the usage lines starting with the command, and the options they use, are kept.
+func (d *DocOpt) Command(name string) *DocOpt {
+	var c DocOpt
+
+	c.Config = d.Config
 
+	usages, err := ParseSynopsis(d.Synopsis)
 	if err != nil {
-		return "", err
+		return nil
+	}
+
+	usages = commandUsages(usages, name)
+	if len(usages) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, u := range usages {
+		lines = append(lines, "  " + u.Program + " " + u.Text)
+	}
+
+	c.Synopsis = strings.Join(lines, "\n")
+
+	used := map[string]bool{}
+	for _, name := range d.synopsisArguments(c.Synopsis) {
+		used[name] = true
+	}
+
+	for _, a := range d.Arguments {
+		if used[a.Name] {
+			c.Arguments = append(c.Arguments, a)
+		}
+	}
+
+	usedFlags := synopsisFlags(usages)
+	for _, s := range d.HelpOptionSections {
+		section := HelpOptionSection{Name: s.Name}
+		for _, o := range s.Options {
+			if o.usedIn(usedFlags) {
+				section.Options = append(section.Options, o)
+			}
+		}
+
+		if len(section.Options) > 0 {
+			c.HelpOptionSections = append(c.HelpOptionSections, section)
+		}
+	}
+
+	return &c
+}
+
+// Validate checks the documented arguments against the placeholders used in the synopsis.
+// Every documented argument must be used in the synopsis, and once an arguments section exists,
+// every placeholder in the synopsis must be documented.
+// Placeholders that belong to an option (e.g. --speed=<kn>, or -D <var> when -D takes a value) are not positional arguments.
+func (d *DocOpt) Validate() []error {
+	var errs []error
+
+	if len(d.Arguments) == 0 {
+		return errs
+	}
+
+	documented := map[string]bool{}
+	for _, a := range d.Arguments {
+		documented[a.Name] = true
+	}
+
+	used := d.synopsisArguments(d.Synopsis)
+	usedSet := map[string]bool{}
+	for _, name := range used {
+		usedSet[name] = true
 	}
 
-	scanner := bufio.NewScanner(file)
+	for _, a := range d.Arguments {
+		if !usedSet[a.Name] {
+			errs = append(errs, fmt.Errorf("argument %s is documented but not used in SYNOPSIS", a.Name))
+		}
+	}
 
-	content, err := ReadLines(scanner)
+	for _, name := range used {
+		if !documented[name] {
+			errs = append(errs, fmt.Errorf("argument %s is used in SYNOPSIS but not documented in ARGUMENTS", name))
+		}
+	}
+
+	return errs
+}
+
+// ConvertRonnFile reads a ronn file, with its includes, and returns its docopt usage string
+func ConvertRonnFile(ronnFile string) (string, error) {
+	content, _, err := ReadRonnFileWithIncludes(ronnFile)
 	if err != nil {
 		return "", err
 	}
@@ -153,13 +394,33 @@ func ConvertRonnFile(ronnFile string) (string, error) {
 	return strings.TrimSpace(d.String()), nil
 }
 
+// ReadRonnFile reads the lines of a ronn file, see ReadLines for how they are normalized
+func ReadRonnFile(ronnFile string) ([]string, error) {
+	file, err := os.Open(ronnFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := ReadLines(NewLineScanner(file, DefaultMaxLineLength))
+	if err != nil {
+		return lines, fmt.Errorf("%s: %s", ronnFile, err)
+	}
+
+	return lines, nil
+}
+
 // ==================================================== //
 // PRIVATE METHODS
 // ---------------------------------------------------- //
 
-func (s *HelpOptionSection) longestOptionNameLen() int {
+func (s *HelpOptionSection) longestOptionNameLen(unlisted map[string]bool) int {
 	l := 0
 	for _, o := range s.Options {
+		if unlisted[o.Name] {
+			continue
+		}
+
 		nl := len(o.Name)
 		if nl > l {
 			l = nl
@@ -169,13 +430,263 @@ func (s *HelpOptionSection) longestOptionNameLen() int {
 	return l
 }
 
-// A section is delimited by section headers
+func (s *HelpOptionSection) hasVisibleOptions() bool {
+	for _, o := range s.Options {
+		if !o.Hidden {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *HelpOptionSection) hasListedOptions(unlisted map[string]bool) bool {
+	for _, o := range s.Options {
+		if !unlisted[o.Name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the hidden options that can be left out of the usage string, and the ones that can't.
+// docopt only accepts the options named in the usage, or listed in Options: when the usage has [options],
+// so a hidden option that is only reachable through [options] stays listed.
+func (d *DocOpt) unlistedOptions() (map[string]bool, []HelpOption) {
+	unlisted := map[string]bool{}
+	var listed []HelpOption
+
+	named := map[string]bool{}
+	shortcut := false
+
+	if usages, err := ParseSynopsis(d.Synopsis); err == nil {
+		for _, u := range usages {
+			walkOptions(u.Pattern, false, func(leaf *Pattern, r bool) {
+				if leaf.Kind == OptionsShortcutPattern {
+					shortcut = true
+					return
+				}
+
+				for _, f := range optionFlags(leaf.Name) {
+					named[f] = true
+				}
+			})
+		}
+	}
+
+	for _, s := range d.HelpOptionSections {
+		for _, o := range s.Options {
+			if !o.Hidden {
+				continue
+			}
+
+			if shortcut && !o.usedIn(named) {
+				listed = append(listed, o)
+				continue
+			}
+
+			unlisted[o.Name] = true
+		}
+	}
+
+	return unlisted, listed
+}
+
+// Hidden options only reachable through [options] are listed in the usage string anyway, see unlistedOptions
+func (d *DocOpt) hiddenOptionDiagnostics() []Diagnostic {
+	var diagnostics []Diagnostic
+
+	_, listed := d.unlistedOptions()
+	for _, o := range listed {
+		diagnostics = append(diagnostics, Diagnostic{
+			Line:    o.Line,
+			Code:    "hidden-option",
+			Message: fmt.Sprintf("option %s is hidden, but only used through [options], so it is listed in the usage anyway", o.Name),
+		})
+	}
+
+	return diagnostics
+}
+
+// e.g. [deprecated: use --speed]
+func (option *HelpOption) deprecationNote() string {
+	if option.Deprecation == "" {
+		return "[deprecated]"
+	}
+
+	return "[deprecated: " + option.Deprecation + "]"
+}
+
+func (d *DocOpt) longestArgumentNameLen() int {
+	l := 0
+	for _, a := range d.Arguments {
+		nl := len(a.Name)
+		if nl > l {
+			l = nl
+		}
+	}
+
+	return l
+}
+
+func (d *DocOpt) longestCommandNameLen() int {
+	l := 0
+	for _, c := range d.Commands {
+		nl := len(c.Name)
+		if nl > l {
+			l = nl
+		}
+	}
+
+	return l
+}
+
+// Fills in each command's usage lines and options from the synopsis tree
+func (d *DocOpt) linkCommands() {
+	if len(d.Commands) == 0 {
+		return
+	}
+
+	usages, err := ParseSynopsis(d.Synopsis)
+	if err != nil {
+		return
+	}
+
+	for i := range d.Commands {
+		c := &d.Commands[i]
+
+		matched := commandUsages(usages, c.Name)
+		for _, u := range matched {
+			c.Usage = append(c.Usage, u.Program + " " + u.Text)
+		}
+
+		usedFlags := synopsisFlags(matched)
+		for _, s := range d.HelpOptionSections {
+			for _, o := range s.Options {
+				if o.usedIn(usedFlags) {
+					c.Options = append(c.Options, o)
+				}
+			}
+		}
+	}
+}
+
+// Binds each option to the environment variable that overrides it
+func (d *DocOpt) linkEnvironment() {
+	for _, e := range d.Environment {
+		if e.Option == "" {
+			continue
+		}
+
+		for i := range d.HelpOptionSections {
+			for j := range d.HelpOptionSections[i].Options {
+				o := &d.HelpOptionSections[i].Options[j]
+				if o.usedIn(map[string]bool{e.Option: true}) {
+					o.Env = e.Name
+				}
+			}
+		}
+	}
+}
+
+// Returns the usage lines starting with the given command
+func commandUsages(usages []UsageLine, command string) []UsageLine {
+	var matched []UsageLine
+
+	for _, u := range usages {
+		if commands := u.Commands(); len(commands) > 0 && commands[0] == command {
+			matched = append(matched, u)
+		}
+	}
+
+	return matched
+}
+
+// Returns the option flags used by the given usage lines
+func synopsisFlags(usages []UsageLine) map[string]bool {
+	flags := map[string]bool{}
+
+	for _, u := range usages {
+		for _, leaf := range u.Pattern.Leaves(OptionPattern) {
+			for _, f := range optionFlags(leaf.Name) {
+				flags[f] = true
+			}
+		}
+	}
+
+	return flags
+}
+
+func (option *HelpOption) usedIn(flags map[string]bool) bool {
+	for _, f := range optionFlags(option.Name) {
+		if flags[f] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the documented option with the flag, or nil
+func (d *DocOpt) documentedOption(flag string) *HelpOption {
+	for _, s := range d.HelpOptionSections {
+		for i := range s.Options {
+			if s.Options[i].usedIn(map[string]bool{flag: true}) {
+				return &s.Options[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// A placeholder written with a space after an option documented as taking a value, e.g. the <var> of "-D <var>",
+// is the value of the option rather than a positional argument
+func (d *DocOpt) isOptionValue(option *Pattern, next *Pattern) bool {
+	if option.Kind != OptionPattern || next.Kind != ArgumentPattern || strings.Contains(option.Name, "=") {
+		return false
+	}
+
+	help := d.documentedOption(option.Name)
+
+	return help != nil && optionTakesValue(help.Name)
+}
+
+// Returns the first of the given sections found, e.g. ARGUMENTS or POSITIONAL ARGUMENTS
+func getFirstSection(lines []string, sectionNames ...string) []string {
+	section, _ := getFirstSectionAt(lines, sectionNames...)
+
+	return section
+}
+
+// Same as getFirstSection, also returning the index of the section's first line in lines
+func getFirstSectionAt(lines []string, sectionNames ...string) ([]string, int) {
+	for _, sectionName := range sectionNames {
+		if section, start := getSectionAt(lines, sectionName); len(section) > 0 {
+			return section, start
+		}
+	}
+
+	return nil, -1
+}
+
+// A section is delimited by section headers.
+// Section names are compared case-insensitively.
 func getSection(lines []string, sectionName string) []string {
+	section, _ := getSectionAt(lines, sectionName)
+
+	return section
+}
+
+// Same as getSection, also returning the index of the section's first line in lines
+func getSectionAt(lines []string, sectionName string) ([]string, int) {
 	var section []string
 
 	sectionFound := false
+	start := -1
 
-	for _, line := range lines {
+	for i, line := range lines {
 		r, s := isSectionHeader(line)
 
 		// if we've reached a new section,
@@ -188,8 +699,9 @@ func getSection(lines []string, sectionName string) []string {
 		// if we've reached a new nection
 		// and it's the desired section, skip this line
 		// then indicate we should start recording the lines
-		if r && s == sectionName {
+		if r && strings.EqualFold(strings.TrimSpace(s), sectionName) {
 			sectionFound = true
+			start = i + 1
 			continue
 		}
 
@@ -198,7 +710,7 @@ func getSection(lines []string, sectionName string) []string {
 		}
 	}
 
-	return section
+	return section, start
 }
 
 func newOption(name string, lines []string) *HelpOption {
@@ -213,15 +725,17 @@ func newOption(name string, lines []string) *HelpOption {
 	return h
 }
 
-func newOptionSection(lines []string) *HelpOptionSection {
+// lineNumber is the (1 based) line number of the first line, used to locate the options in the source
+func newOptionSection(lines []string, lineNumber int) *HelpOptionSection {
 	h := &HelpOptionSection{}
 
 	var sectionDescriptionLines []string
 	var prevOptionName string
+	var prevOptionLine int
 	var optionLines []string
 	inSectionDesc := true
 
-	for _, line := range lines {
+	for i, line := range lines {
 		if f, newOptionName := isOptionDeclaration(line); f {
 
 			// the first option found basically kicks off the line "collection"
@@ -229,11 +743,13 @@ func newOptionSection(lines []string) *HelpOptionSection {
 			// from previously collected lines
 			if len(optionLines) > 0 {
 				o := newOption(prevOptionName, optionLines)
+				o.Line = prevOptionLine
 				h.Options = append(h.Options, *o)
 				optionLines = nil
 			}
 
 			prevOptionName = newOptionName
+			prevOptionLine = lineNumber + i
 			inSectionDesc = false
 			continue
 		}
@@ -249,6 +765,7 @@ func newOptionSection(lines []string) *HelpOptionSection {
 	// append final option to list
 	if len(prevOptionName) > 0 {
 		o := newOption(prevOptionName, optionLines)
+		o.Line = prevOptionLine
 		h.Options = append(h.Options, *o)
 	}
 
@@ -260,6 +777,97 @@ func newOptionSection(lines []string) *HelpOptionSection {
 	return h
 }
 
+func newArguments(lines []string) []HelpArgument {
+	var arguments []HelpArgument
+
+	collectDeclarations(lines, isArgumentDeclaration, func(name string, lines []string) {
+		arguments = append(arguments, HelpArgument{
+			Name: name,
+			Desc: shortDescription(lines),
+		})
+	})
+
+	return arguments
+}
+
+func newCommands(lines []string) []HelpCommand {
+	var commands []HelpCommand
+
+	collectDeclarations(lines, isCommandDeclaration, func(name string, lines []string) {
+		commands = append(commands, HelpCommand{
+			Name: name,
+			Desc: shortDescription(lines),
+		})
+	})
+
+	return commands
+}
+
+func newEnvironment(lines []string) []HelpEnvironment {
+	var environment []HelpEnvironment
+
+	collectDeclarations(lines, isEnvironmentDeclaration, func(name string, lines []string) {
+		e := HelpEnvironment{
+			Name: name,
+			Desc: shortDescription(lines),
+		}
+
+		for _, line := range lines {
+			if ma := NamedMatches(overridesOptionRe, overridesOptionMa, line); len(ma) > 0 {
+				e.Option = ma["option"]
+				break
+			}
+		}
+
+		environment = append(environment, e)
+	})
+
+	return environment
+}
+
+// Collects the lines following each declaration (e.g. an argument or command bullet),
+// calling fn once per declaration. Lines before the first declaration are ignored.
+func collectDeclarations(lines []string, isDeclaration func(string) (bool, string), fn func(string, []string)) {
+	var prevName string
+	var declarationLines []string
+
+	for _, line := range lines {
+		if f, newName := isDeclaration(line); f {
+			if len(prevName) > 0 {
+				fn(prevName, declarationLines)
+				declarationLines = nil
+			}
+
+			prevName = newName
+			continue
+		}
+
+		if len(prevName) > 0 {
+			declarationLines = append(declarationLines, line)
+		}
+	}
+
+	// finalize last declaration
+	if len(prevName) > 0 {
+		fn(prevName, declarationLines)
+	}
+}
+
+// The short description is the first sentence of the first non-blank line
+func shortDescription(lines []string) string {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		short, _ := firstSentence(line)
+
+		return strings.TrimSpace(short)
+	}
+
+	return ""
+}
+
 // A Section Header is a markdown H2 e.g.
 // ## Foo
 func isSectionHeader(line string) (bool, string) {
@@ -271,9 +879,9 @@ func isSectionHeader(line string) (bool, string) {
 	return false, ""
 }
 
-// A Section Description Line is a line that does not begin with any spaces (and is not a section header)
+// A Section Description Line is a line that does not begin with any spaces (and is not a section header or option declaration)
 func isSectionDescriptionLine(line string) bool {
-	if strings.HasPrefix(line, " ") {
+	if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
 		return false
 	}
 
@@ -281,6 +889,10 @@ func isSectionDescriptionLine(line string) bool {
 		return false
 	}
 
+	if oD, _ := isOptionDeclaration(line); oD {
+		return false
+	}
+
 	if strings.TrimSpace(line) == "" {
 		return false
 	}
@@ -290,12 +902,12 @@ func isSectionDescriptionLine(line string) bool {
 
 // An Option Declaration looks like this:
 //   * `--help`:
+// Any indentation, and the -, + bullets are accepted too
 func isOptionDeclaration(line string) (bool, string) {
 	ma := NamedMatches(namedOptionRe, namedOptionMa, line)
 
 	if len(ma) > 0 {
-		name := strings.Replace(ma["name"], "`", "", -1)
-		name = strings.Replace(name, ",", "", -1)
+		name := strings.Replace(PlainInline(ma["name"]), ",", "", -1)
 
 		return true, name
 	}
@@ -303,16 +915,180 @@ func isOptionDeclaration(line string) (bool, string) {
 	return false, ""
 }
 
+// An Argument Declaration looks like this:
+//   * `<name>`:
+func isArgumentDeclaration(line string) (bool, string) {
+	ma := NamedMatches(namedArgumentRe, namedArgumentMa, line)
+
+	if len(ma) > 0 {
+		return true, PlainInline(ma["name"])
+	}
+
+	return false, ""
+}
+
+// A Command Declaration looks like this:
+//   * `ship`:
+func isCommandDeclaration(line string) (bool, string) {
+	ma := NamedMatches(namedCommandRe, namedCommandMa, line)
+
+	if len(ma) > 0 {
+		return true, ma["name"]
+	}
+
+	return false, ""
+}
+
+// An Environment Declaration looks like this:
+//   * `NAVAL_SPEED`:
+// The variable is bound to an option when its description says e.g. "Overrides --speed."
+func isEnvironmentDeclaration(line string) (bool, string) {
+	ma := NamedMatches(namedEnvironmentRe, namedEnvironmentMa, line)
+
+	if len(ma) > 0 {
+		return true, ma["name"]
+	}
+
+	return false, ""
+}
+
+// Returns the positional argument placeholders used in the synopsis, in order of first use.
+// The value of an option written with a space, e.g. the <var> of "-D <var>", is not positional.
+func (d *DocOpt) synopsisArguments(synopsis string) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, line := range strings.Split(synopsis, "\n") {
+		for _, m := range synopsisArgumentRe.FindAllStringSubmatchIndex(line, -1) {
+			name := line[m[4]:m[5]]
+
+			if before := tokenizeSynopsis(line[:m[4]]); len(before) > 0 {
+				if d.isOptionValue(newLeafPattern(before[len(before)-1]), newLeafPattern(name)) {
+					continue
+				}
+			}
+
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Option bullets should all have the same indentation, and option bodies should be indented more than the bullets.
+// Content is never dropped because of its indentation, but ambiguous indentation is reported.
+// lineNumber is the (1 based) line number of the first line.
+func optionIndentationDiagnostics(lines []string, lineNumber int) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	bulletIndent := -1
+	inOption := false
+	option := ""
+	afterBlank := false
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			afterBlank = true
+			continue
+		}
+
+		blank := afterBlank
+		afterBlank = false
+
+		leading := line[:len(line) - len(strings.TrimLeft(line, " \t"))]
+		if strings.Contains(leading, " ") && strings.Contains(leading, "\t") {
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:    lineNumber + i,
+				Code:    "indentation",
+				Message: fmt.Sprintf("indentation mixes tabs and spaces, tabs are expanded to %d columns", tabWidth),
+			})
+		}
+
+		expanded := ExpandTabs(line, tabWidth)
+		indent := len(expanded) - len(strings.TrimLeft(expanded, " "))
+
+		if f, name := isOptionDeclaration(expanded); f {
+			if bulletIndent == -1 {
+				bulletIndent = indent
+			} else if indent != bulletIndent {
+				diagnostics = append(diagnostics, Diagnostic{
+					Line:    lineNumber + i,
+					Code:    "indentation",
+					Message: fmt.Sprintf("option %s is indented %d spaces, the first option is indented %d", name, indent, bulletIndent),
+				})
+			}
+
+			inOption = true
+			option = name
+			continue
+		}
+
+		// right under an option, a flush left line is likely its body, but it's read as an option section description.
+		// After a blank line, it's taken to start an option section, e.g. "Advanced Options:"
+		if indent == 0 {
+			if inOption && !blank {
+				if h, _ := isSectionHeader(expanded); !h {
+					diagnostics = append(diagnostics, Diagnostic{
+						Line:    lineNumber + i,
+						Code:    "indentation",
+						Message: fmt.Sprintf("ambiguous indentation, the line after option %s is not indented, so it's read as an option section description, not the option body", option),
+					})
+				}
+			}
+
+			inOption = false
+			continue
+		}
+
+		if inOption && indent <= bulletIndent {
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:    lineNumber + i,
+				Code:    "indentation",
+				Message: fmt.Sprintf("ambiguous indentation, the option body is indented %d spaces, not more than the option bullet (%d)", indent, bulletIndent),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// Lines that were not read with ReadLines may still have a byte order mark, or CR line endings
+func normalizeLines(lines []string) []string {
+	var normalized []string
+
+	for i, line := range lines {
+		if i == 0 {
+			line = strings.TrimPrefix(line, byteOrderMark)
+		}
+
+		normalized = append(normalized, strings.TrimSuffix(line, "\r"))
+	}
+
+	return normalized
+}
+
+func expandTabLines(lines []string) []string {
+	var expanded []string
+
+	for _, line := range lines {
+		expanded = append(expanded, ExpandTabs(line, tabWidth))
+	}
+
+	return expanded
+}
 
 // The Synopsis should be stripped of specific markdown/html syntax:
-// * backticks (`)
 // * html line break (<br>)
+// * inline markup, e.g. backticks (`)
 // It also should be formatted such that each line begins with 2 spaces.
 func formatSynopsis(lines []string) string {
 	var buffer bytes.Buffer
 	for _, line := range lines {
-		line = strings.Replace(line, "`", "", -1)
 		line = brRe.ReplaceAllString(line, "$1")
+		line = PlainInline(line)
 		line = strings.TrimSpace(line)
 
 		if line != "" {
@@ -330,9 +1106,28 @@ func formatSynopsis(lines []string) string {
 }
 
 func (option *HelpOption) updateWithLine(line string) {
+	// annotations can be anywhere in the description, e.g. before the default
+	for _, m := range annotationRe.FindAllStringSubmatch(line, -1) {
+		switch m[1] {
+		case "choices":
+			if option.Choices == nil {
+				option.Choices = parseChoices(m[2])
+			}
+		case "deprecated":
+			option.Deprecated = true
+			option.Deprecation = strings.TrimSpace(m[2])
+		case "hidden":
+			option.Hidden = true
+		case "repeatable":
+			option.Cardinality = repeatedCardinality(option.Name)
+		}
+	}
+	line = annotationRe.ReplaceAllString(line, "")
+
 	if option.Desc == "" {
-		if ma := NamedMatches(shortOptionDescRe, shortOptionDescMa, line); len(ma) > 0 {
-			option.Desc = strings.TrimSpace(ma["short"])
+		// body lines are tab expanded, and indented by any number of spaces
+		if short, ok := firstSentence(line); ok && strings.HasPrefix(line, " ") {
+			option.Desc = strings.TrimSpace(short)
 		} else if ma := NamedMatches(defaultValueRe, defaultValueMa, line); len(ma) > 0 {
 			option.Desc = strings.TrimSpace(ma["before"])
 			option.DefaultValue = ma["default"]
@@ -349,3 +1144,16 @@ func (option *HelpOption) updateWithLine(line string) {
 		}
 	}
 }
+
+// e.g. "json, yaml, text" returns [json yaml text]
+func parseChoices(s string) []string {
+	var choices []string
+
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			choices = append(choices, c)
+		}
+	}
+
+	return choices
+}
@@ -55,6 +55,35 @@ var exampleFile = append(append([]string{
 	"",
 }, optionSection...), []string{"## Another Section"}...)
 
+var argumentSection = []string{
+	"## ARGUMENTS",
+	"",
+	"  * `<name>`:",
+	"    Name of the ship. Must be unique.",
+	"",
+	"  * `<x>`:",
+	"    X coordinate",
+	"",
+	"  * <y>:",
+	"    Y coordinate.",
+	"",
+}
+
+var commandSection = []string{
+	"## COMMANDS",
+	"",
+	"  * `ship`:",
+	"    Create, move and shoot ships. Ships are named.",
+	"",
+	"  * mine:",
+	"    Set or remove mines",
+	"",
+}
+
+var exampleFileWithCommands = append(append(append([]string{}, exampleFile[:16]...), commandSection...), exampleFile[16:]...)
+
+var exampleFileWithArguments = append(append(append([]string{}, exampleFile[:16]...), argumentSection...), exampleFile[16:]...)
+
 func ExampleIsSectionHeader() {
 	for _, line := range exampleFile {
 		matched, section := isSectionHeader(line)
@@ -86,6 +115,33 @@ func ExampleIsOptionDeclaration() {
 	// -b
 }
 
+func Example_isArgumentDeclaration() {
+	for _, line := range exampleFileWithArguments {
+		matched, name := isArgumentDeclaration(line)
+		if matched {
+			fmt.Println(name)
+		}
+	}
+
+	// Output:
+	// <name>
+	// <x>
+	// <y>
+}
+
+func Example_isCommandDeclaration() {
+	for _, line := range exampleFileWithCommands {
+		matched, name := isCommandDeclaration(line)
+		if matched {
+			fmt.Println(name)
+		}
+	}
+
+	// Output:
+	// ship
+	// mine
+}
+
 func TestIsSectionDescriptionLine(t *testing.T) {
 	got := ""
 
@@ -310,6 +366,74 @@ func TestRonnToDocopt(t *testing.T) {
 		}
 	})
 	
+	t.Run("example file with arguments has 3 arguments", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+
+		got := len(d.Arguments)
+		want := 3
+		if got != want {
+			t.Errorf("number of arguments got = %d, want %d", got, want)
+			return
+		}
+
+		gotDesc := d.Arguments[0].Desc
+		wantDesc := "Name of the ship."
+		if gotDesc != wantDesc {
+			t.Errorf("argument.Desc got = %s, want %s", gotDesc, wantDesc)
+		}
+	})
+
+	t.Run("arguments section can be named POSITIONAL ARGUMENTS", func(t *testing.T) {
+		lines := append([]string{"## POSITIONAL ARGUMENTS"}, argumentSection[1:]...)
+		d := RonnToDocopt(lines)
+
+		got := len(d.Arguments)
+		want := 3
+		if got != want {
+			t.Errorf("number of arguments got = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("example file with commands has 2 commands", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithCommands)
+
+		got := len(d.Commands)
+		want := 2
+		if got != want {
+			t.Errorf("number of commands got = %d, want %d", got, want)
+			return
+		}
+
+		gotDesc := d.Commands[0].Desc
+		wantDesc := "Create, move and shoot ships."
+		if gotDesc != wantDesc {
+			t.Errorf("command.Desc got = %s, want %s", gotDesc, wantDesc)
+		}
+	})
+
+	t.Run("commands are linked to the synopsis", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithCommands)
+
+		ship := d.Commands[0]
+
+		got := len(ship.Usage)
+		want := 3
+		if got != want {
+			t.Errorf("number of ship usage lines got = %d, want %d", got, want)
+		}
+
+		got = len(ship.Options)
+		want = 1
+		if got != want {
+			t.Errorf("number of ship options got = %d, want %d", got, want)
+			return
+		}
+
+		if ship.Options[0].Name != "--speed=<kn>" {
+			t.Errorf("ship option got = %s, want --speed=<kn>", ship.Options[0].Name)
+		}
+	})
+
 	t.Run("options section", func(t *testing.T) {
 		t.Run("when starts with an option", func(t *testing.T) {
 			t.Skip()
@@ -372,6 +496,83 @@ func TestDocOpt_String(t *testing.T) {
 		}
 	})
 
+	t.Run("when has arguments", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+		got := d.String()
+
+		want := "Usage:\n" +
+				"  naval_fate ship new <name>...\n" +
+				"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+				"  naval_fate ship shoot <x> <y>\n" +
+				"  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]\n" +
+				"  naval_fate -h | --help\n" +
+				"  naval_fate --version\n" +
+				"\n" +
+				"Arguments:\n" +
+				"  <name>  Name of the ship.\n" +
+				"  <x>     X coordinate\n" +
+				"  <y>     Y coordinate.\n" +
+				"\n" +
+				"Options:\n" +
+				"  -h --help     Show this screen.\n" +
+				"  --version     Show version.\n" +
+				"  --speed=<kn>  Speed in knots. [default: 10]\n" +
+				"  --foo         Multiline description\n" +
+				"\n" +
+				"Other Options\n" +
+				"  -b  Thingy [default: baz]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when has commands", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithCommands)
+		got := d.String()
+
+		want := "Usage:\n" +
+				"  naval_fate ship new <name>...\n" +
+				"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+				"  naval_fate ship shoot <x> <y>\n" +
+				"  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]\n" +
+				"  naval_fate -h | --help\n" +
+				"  naval_fate --version\n" +
+				"\n" +
+				"Commands:\n" +
+				"  ship  Create, move and shoot ships.\n" +
+				"  mine  Set or remove mines\n" +
+				"\n" +
+				"Options:\n" +
+				"  -h --help     Show this screen.\n" +
+				"  --version     Show version.\n" +
+				"  --speed=<kn>  Speed in knots. [default: 10]\n" +
+				"  --foo         Multiline description\n" +
+				"\n" +
+				"Other Options\n" +
+				"  -b  Thingy [default: baz]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
 	t.Run("when starts with an option", func(t *testing.T) {
 		t.Skip()
 	})
@@ -385,3 +586,465 @@ func TestDocOpt_String(t *testing.T) {
 	})
 
 }
+
+func TestDocOpt_String_InlineMarkup(t *testing.T) {
+	page := []string{
+		"## SYNOPSIS",
+		"",
+		"`naval_fate` `ship <name> move [--speed=<kn>]`<br>",
+		"",
+		"## OPTIONS",
+		"",
+		"  * `--speed=<kn>`:",
+		"    Speed in **knots**, see [docopt](http://docopt.org/a.html). [default: 10]",
+		"  * --shape=&lt;shape&gt;:",
+		"    Shape of the _ship_, in `a.b` style. More text.",
+	}
+
+	d := RonnToDocopt(page)
+
+	want := `Usage:
+  naval_fate ship <name> move [--speed=<kn>]
+
+Options:
+  --speed=<kn>     Speed in knots, see docopt. [default: 10]
+  --shape=<shape>  Shape of the ship, in a.b style.`
+
+	got := d.String()
+	if got != want {
+		t.Errorf("got = %q, want %q", got, want)
+	}
+
+	if !strings.Contains(d.ANSI(), "Speed in \x1b[1mknots\x1b[22m") {
+		t.Errorf("ANSI got = %q, want bold knots", d.ANSI())
+	}
+}
+
+func TestDocOpt_Command(t *testing.T) {
+	t.Run("subcommand usage", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+		got := d.Command("ship").String()
+
+		want := "Usage:\n" +
+				"  naval_fate ship new <name>...\n" +
+				"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+				"  naval_fate ship shoot <x> <y>\n" +
+				"\n" +
+				"Arguments:\n" +
+				"  <name>  Name of the ship.\n" +
+				"  <x>     X coordinate\n" +
+				"  <y>     Y coordinate.\n" +
+				"\n" +
+				"Options:\n" +
+				"  --speed=<kn>  Speed in knots. [default: 10]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("when command is not in the synopsis", func(t *testing.T) {
+		d := RonnToDocopt(exampleFile)
+
+		if got := d.Command("dock"); got != nil {
+			t.Errorf("got = %v, want nil", got)
+		}
+	})
+}
+
+func TestRonnToDocopt_Indentation(t *testing.T) {
+	want := []HelpOption{
+		{Name: "-h --help", Desc: "Show this screen."},
+		{Name: "--speed=<kn>", Desc: "Speed in knots.", DefaultValue: "[default: 10]", Default: Default{Kind: IntDefault, Text: "10", Value: int64(10)}},
+	}
+
+	options := func(d *DocOpt) []HelpOption {
+		var got []HelpOption
+		for _, s := range d.HelpOptionSections {
+			for _, o := range s.Options {
+				o.Line = 0
+				got = append(got, o)
+			}
+		}
+		return got
+	}
+
+	pages := map[string][]string{
+		"when indented with tabs": {
+			"## OPTIONS",
+			"\t* `-h`, `--help`:",
+			"\t\tShow this screen.",
+			"\t* `--speed=<kn>`:",
+			"\t\tSpeed in knots. [default: 10]",
+		},
+		"when using - bullets and 3 space indentation": {
+			"## OPTIONS",
+			"   - `-h`, `--help`:",
+			"      Show this screen.",
+			"   - `--speed=<kn>`:",
+			"      Speed in knots. [default: 10]",
+		},
+		"when using + bullets without indentation": {
+			"## OPTIONS",
+			"+ `-h`, `--help`:",
+			"  Show this screen.",
+			"+ `--speed=<kn>`:",
+			"  Speed in knots. [default: 10]",
+		},
+	}
+
+	for name, page := range pages {
+		t.Run(name, func(t *testing.T) {
+			d := RonnToDocopt(page)
+
+			got := options(d)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("options got = %v, want %v", got, want)
+			}
+
+			if len(d.Diagnostics) != 0 {
+				t.Errorf("diagnostics got = %v, want none", d.Diagnostics)
+			}
+		})
+	}
+
+	t.Run("when lines have CR line endings", func(t *testing.T) {
+		var page []string
+		for _, line := range pages["when using + bullets without indentation"] {
+			page = append(page, line+"\r")
+		}
+		page[0] = "\uFEFF" + page[0]
+
+		got := options(RonnToDocopt(page))
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("options got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("when indentation is ambiguous", func(t *testing.T) {
+		page := []string{
+			"## OPTIONS",
+			"  * `-h`, `--help`:",
+			"  Show this screen.",
+			"    * `--speed=<kn>`:",
+			"  \tSpeed in knots. [default: 10]",
+		}
+
+		d := RonnToDocopt(page)
+
+		got := options(d)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("options got = %v, want %v", got, want)
+		}
+
+		var diagnostics []string
+		for _, diagnostic := range d.Diagnostics {
+			diagnostics = append(diagnostics, diagnostic.String())
+		}
+
+		wantDiagnostics := []string{
+			"3: ambiguous indentation, the option body is indented 2 spaces, not more than the option bullet (2)",
+			"4: option --speed=<kn> is indented 4 spaces, the first option is indented 2",
+			"5: indentation mixes tabs and spaces, tabs are expanded to 4 columns",
+		}
+
+		if fmt.Sprint(diagnostics) != fmt.Sprint(wantDiagnostics) {
+			t.Errorf("diagnostics got = %q, want %q", diagnostics, wantDiagnostics)
+		}
+	})
+
+	t.Run("when the option body is not indented", func(t *testing.T) {
+		page := []string{
+			"## OPTIONS",
+			"  * `--speed=<kn>`:",
+			"Speed in knots. [default: 10]",
+			"",
+			"Advanced Options:",
+			"",
+			"  * `--moored`:",
+			"    Moored (anchored) mine.",
+		}
+
+		var got []string
+		for _, diagnostic := range RonnToDocopt(page).Diagnostics {
+			got = append(got, diagnostic.Code+" "+diagnostic.String())
+		}
+
+		want := []string{
+			"indentation 3: ambiguous indentation, the line after option --speed=<kn> is not indented, so it's read as an option section description, not the option body",
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("diagnostics got = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestDocOpt_Validate(t *testing.T) {
+	t.Run("when all arguments are documented", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+
+		got := d.Validate()
+		if len(got) != 0 {
+			t.Errorf("errors got = %v, want none", got)
+		}
+	})
+
+	t.Run("when there is no arguments section", func(t *testing.T) {
+		d := RonnToDocopt(exampleFile)
+
+		got := d.Validate()
+		if len(got) != 0 {
+			t.Errorf("errors got = %v, want none", got)
+		}
+	})
+
+	t.Run("when an argument is not used in the synopsis", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+		d.Arguments = append(d.Arguments, HelpArgument{Name: "<z>"})
+
+		got := d.Validate()
+		if len(got) != 1 {
+			t.Errorf("number of errors got = %d, want 1", len(got))
+			return
+		}
+
+		want := "argument <z> is documented but not used in SYNOPSIS"
+		if got[0].Error() != want {
+			t.Errorf("error got = %s, want %s", got[0], want)
+		}
+	})
+
+	t.Run("when a synopsis placeholder is not documented", func(t *testing.T) {
+		d := RonnToDocopt(exampleFileWithArguments)
+		d.Arguments = d.Arguments[1:]
+
+		got := d.Validate()
+		if len(got) != 1 {
+			t.Errorf("number of errors got = %d, want 1", len(got))
+			return
+		}
+
+		want := "argument <name> is used in SYNOPSIS but not documented in ARGUMENTS"
+		if got[0].Error() != want {
+			t.Errorf("error got = %s, want %s", got[0], want)
+		}
+	})
+
+	t.Run("when an option value is written with a space", func(t *testing.T) {
+		lines := []string{
+			"## SYNOPSIS",
+			"",
+			"`tool` `[-D <var>]... <file>`",
+			"",
+			"## ARGUMENTS",
+			"",
+			"  * `<file>`:",
+			"    The file to read.",
+			"",
+			"## OPTIONS",
+			"",
+			"  * `-D <var>`:",
+			"    Set a variable.",
+			"",
+		}
+
+		got := RonnToDocopt(lines).Validate()
+		if len(got) != 0 {
+			t.Errorf("errors got = %v, want none", got)
+		}
+	})
+}
+
+var choicesPage = []string{
+	"## SYNOPSIS",
+	"",
+	"`render` `[--format=<fmt>] [--level=<n>] <file>`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `-f`, `--format=<fmt>`:",
+	"    Output format [choices: json, yaml, text] [default: json]",
+	"  * `--level=<n>`:",
+	"    Compression level. [choices: 1, 2, 3]",
+	"    [default: 2]",
+	"",
+}
+
+func TestRonnToDocopt_Choices(t *testing.T) {
+	d := RonnToDocopt(choicesPage)
+
+	t.Run("parses the choices out of the description", func(t *testing.T) {
+		var got []string
+		for _, o := range d.HelpOptionSections[0].Options {
+			got = append(got, fmt.Sprintf("%s|%s|%s|%q", o.Name, o.Desc, o.DefaultValue, o.Choices))
+		}
+
+		want := []string{
+			`-f --format=<fmt>|Output format|[default: json]|["json" "yaml" "text"]`,
+			`--level=<n>|Compression level.|[default: 2]|["1" "2" "3"]`,
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("options got = %q, want %q", got, want)
+		}
+
+		if len(d.Diagnostics) != 0 {
+			t.Errorf("diagnostics got = %v, want none", d.Diagnostics)
+		}
+	})
+
+	t.Run("renders the choices before the default", func(t *testing.T) {
+		got := d.String()
+
+		want := "Usage:\n" +
+			"  render [--format=<fmt>] [--level=<n>] <file>\n" +
+			"\n" +
+			"Options:\n" +
+			"  -f --format=<fmt>  Output format [choices: json, yaml, text] [default: json]\n" +
+			"  --level=<n>        Compression level. [choices: 1, 2, 3] [default: 2]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+}
+
+var annotatedPage = []string{
+	"## SYNOPSIS",
+	"",
+	"`thing` `[options] <file>`",
+	"",
+	"## OPTIONS",
+	"",
+	"  * `--speed=<kn>`:",
+	"    Speed in knots.",
+	"  * `--velocity=<kn>`:",
+	"    Speed in knots. [deprecated: use --speed]",
+	"  * `--debug`:",
+	"    Dump internal state. [hidden]",
+	"",
+	"Internal options",
+	"",
+	"  * `--trace`:",
+	"    [hidden] Trace everything.",
+	"",
+}
+
+func TestRonnToDocopt_Annotations(t *testing.T) {
+	d := RonnToDocopt(annotatedPage)
+
+	t.Run("parses deprecated and hidden options", func(t *testing.T) {
+		var got []string
+		for _, s := range d.HelpOptionSections {
+			for _, o := range s.Options {
+				got = append(got, fmt.Sprintf("%s|%s|%t|%s|%t", o.Name, o.Desc, o.Deprecated, o.Deprecation, o.Hidden))
+			}
+		}
+
+		want := []string{
+			"--speed=<kn>|Speed in knots.|false||false",
+			"--velocity=<kn>|Speed in knots.|true|use --speed|false",
+			"--debug|Dump internal state.|false||true",
+			"--trace|Trace everything.|false||true",
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("options got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("keeps hidden options only used through [options] and flags deprecated options", func(t *testing.T) {
+		got := d.String()
+
+		want := "Usage:\n" +
+			"  thing [options] <file>\n" +
+			"\n" +
+			"Options:\n" +
+			"  --speed=<kn>     Speed in knots.\n" +
+			"  --velocity=<kn>  Speed in knots. [deprecated: use --speed]\n" +
+			"  --debug          Dump internal state.\n" +
+			"\n" +
+			"Internal options\n" +
+			"  --trace  Trace everything."
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("reports hidden options only used through [options]", func(t *testing.T) {
+		var got []string
+		for _, diagnostic := range d.Diagnostics {
+			got = append(got, fmt.Sprintf("%d: %s", diagnostic.Line, diagnostic.Code))
+		}
+
+		want := []string{"11: hidden-option", "16: hidden-option"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("diagnostics got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("omits hidden options named in the synopsis", func(t *testing.T) {
+		lines := append([]string{
+			"## SYNOPSIS",
+			"",
+			"`thing` `[options] [--debug] [--trace] <file>`",
+		}, annotatedPage[3:]...)
+
+		got := RonnToDocopt(lines).String()
+
+		want := "Usage:\n" +
+			"  thing [options] [--debug] [--trace] <file>\n" +
+			"\n" +
+			"Options:\n" +
+			"  --speed=<kn>     Speed in knots.\n" +
+			"  --velocity=<kn>  Speed in knots. [deprecated: use --speed]"
+
+		if got != want {
+			diff := difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			}
+			text, _ := difflib.GetUnifiedDiffString(diff)
+
+			fmt.Println(text)
+			t.Error()
+		}
+	})
+
+	t.Run("keeps hidden options in the man page", func(t *testing.T) {
+		roff := ParseDocument(annotatedPage).Roff(RoffOptions{})
+
+		for _, want := range []string{"\\fB\\-\\-debug\\fR", "\\fB\\-\\-trace\\fR"} {
+			if !strings.Contains(roff, want) {
+				t.Errorf("man page doesn't contain %q", want)
+			}
+		}
+	})
+}
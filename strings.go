@@ -4,8 +4,15 @@ import (
 	"regexp"
 	"strings"
 	"bufio"
+	"fmt"
+	"io"
 )
 
+// The longest line NewLineScanner reads by default
+const DefaultMaxLineLength = 1024 * 1024
+
+const byteOrderMark = "\uFEFF"
+
 func RegexAndMatchNames(pattern string) (*regexp.Regexp, []string) {
 	var re = regexp.MustCompile(pattern)
 	var ma = re.SubexpNames()
@@ -41,11 +48,67 @@ func PadRight(str string, pad string, length int) string {
 	return str + padding
 }
 
+// Replaces tabs with spaces, up to the next tab stop
+func ExpandTabs(str string, tabWidth int) string {
+	if !strings.Contains(str, "\t") {
+		return str
+	}
+
+	var expanded []rune
+	column := 0
+
+	for _, r := range str {
+		if r == '\t' {
+			spaces := tabWidth - column % tabWidth
+			expanded = append(expanded, []rune(strings.Repeat(" ", spaces))...)
+			column += spaces
+			continue
+		}
+
+		expanded = append(expanded, r)
+		column++
+	}
+
+	return string(expanded)
+}
+
+// NewLineScanner returns a line scanner that accepts lines up to maxLineLength bytes, or any length when maxLineLength <= 0
+func NewLineScanner(r io.Reader, maxLineLength int) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+
+	if maxLineLength <= 0 {
+		maxLineLength = int(^uint(0) >> 1)
+	}
+
+	size := 4096
+	if maxLineLength < size {
+		size = maxLineLength
+	}
+
+	scanner.Buffer(make([]byte, 0, size), maxLineLength)
+
+	return scanner
+}
+
+// ReadLines reads all the lines of the scanner, normalizing them:
+// a UTF-8 byte order mark is removed, and CRLF and CR line endings are treated as LF.
+// Errors of the scanner, e.g. bufio.ErrTooLong, are returned with the lines read so far.
 func ReadLines(scanner *bufio.Scanner) ([]string, error) {
 	var lines []string
 
 	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+		text := scanner.Text()
+		if len(lines) == 0 {
+			text = strings.TrimPrefix(text, byteOrderMark)
+		}
+
+		// a CR only file is a single line to the scanner
+		text = strings.TrimSuffix(text, "\r")
+		lines = append(lines, strings.Split(text, "\r")...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return lines, fmt.Errorf("line %d: %s", len(lines) + 1, err)
 	}
 
 	return lines, nil
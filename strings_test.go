@@ -1,6 +1,8 @@
 package ronn2docopt
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -51,6 +53,28 @@ func TestNamedMatches(t *testing.T) {
 	})
 }
 
+func TestExpandTabs(t *testing.T) {
+	t.Run("when string has no tabs", func(t *testing.T) {
+		got := ExpandTabs("  * `--help`:", 4)
+
+		want := "  * `--help`:"
+
+		if got != want {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when tabs follow other characters", func(t *testing.T) {
+		got := ExpandTabs("\t*\t`--help`:", 4)
+
+		want := "    *   `--help`:"
+
+		if got != want {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+}
+
 func TestPadRight(t *testing.T) {
 	str := "foo"
 
@@ -94,3 +118,60 @@ func TestPadRight(t *testing.T) {
 		}
 	})
 }
+
+func TestReadLines(t *testing.T) {
+	t.Run("when page has a byte order mark and CRLF line endings", func(t *testing.T) {
+		got, err := ReadLines(NewLineScanner(strings.NewReader("\uFEFF## OPTIONS\r\n  * `--help`:\r\n"), DefaultMaxLineLength))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"## OPTIONS", "  * `--help`:"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when page has CR line endings", func(t *testing.T) {
+		got, err := ReadLines(NewLineScanner(strings.NewReader("## OPTIONS\r  * `--help`:\r"), DefaultMaxLineLength))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"## OPTIONS", "  * `--help`:"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when a line is longer than the default scanner buffer", func(t *testing.T) {
+		long := strings.Repeat("x", 100*1024)
+
+		got, err := ReadLines(NewLineScanner(strings.NewReader("a\n"+long+"\nb\n"), 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != 3 || got[1] != long {
+			t.Errorf("number of lines got = %d, want 3", len(got))
+		}
+	})
+
+	t.Run("when a line is longer than the max line length", func(t *testing.T) {
+		got, err := ReadLines(NewLineScanner(strings.NewReader("a\n"+strings.Repeat("x", 100)+"\nb\n"), 10))
+		if err == nil {
+			t.Fatal("error got = nil, want bufio.ErrTooLong")
+		}
+
+		want := "line 2: bufio.Scanner: token too long"
+		if err.Error() != want {
+			t.Errorf("error got = %s, want %s", err, want)
+		}
+
+		if !reflect.DeepEqual(got, []string{"a"}) {
+			t.Errorf("lines got = %q, want [a]", got)
+		}
+	})
+}
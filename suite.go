@@ -0,0 +1,467 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// e.g. naval_fate(6), in a SEE ALSO section
+var pageReferenceRe = regexp.MustCompile(`([\w.:-]+)\((\d\w*)\)`)
+var pageFileSuffixRe = regexp.MustCompile(`(\.\d\w*)?\.ronn$`)
+
+// A Page is a ronn page of a suite, Name is the page name, e.g. naval_fate-ship
+type Page struct {
+	Name  string
+	Lines []string
+}
+
+// A Suite is a git style command suite, documented by a page per command, e.g.
+// naval_fate.1.ronn, naval_fate-ship.1.ronn and naval_fate-mine.1.ronn.
+// Usage is the combined usage of the program, and Commands the usage of each subcommand, in order of their path.
+type Suite struct {
+	Program  string
+	Usage    *DocOpt
+	Commands []SuiteCommand
+}
+
+// A SuiteCommand is a subcommand documented by its own page.
+// Path is the commands leading to it, e.g. [ship] for naval_fate-ship, [ship new] for naval_fate-ship-new.
+type SuiteCommand struct {
+	Path    []string
+	Page    string
+	Tagline string
+	Usage   *DocOpt
+}
+
+type suitePage struct {
+	Page
+	tagline string
+	docopt  *DocOpt
+	parent  *suitePage
+	command string
+
+	// the commands of the first usage line, for pages linked by SEE ALSO
+	synopsisCommands []string
+}
+
+// PageName is the name in the title line of the page, or else the name of the file without its man section
+func PageName(ronnFile string, lines []string) string {
+	if name := ParseDocument(lines).Name; name != "" {
+		return name
+	}
+
+	return pageFileSuffixRe.ReplaceAllString(filepath.Base(ronnFile), "")
+}
+
+// NewSuite links the subcommand pages to their parent page, and merges them into a single usage.
+// A page is the subcommand of the page it's named after, e.g. naval_fate-ship is the ship command of naval_fate.
+// Otherwise, a page is a subcommand of the first page of the suite referenced in its SEE ALSO section,
+// unless other pages are named after it, e.g. naval_fate referencing naval_fate-ship(6),
+// and the command is the one following the parent's commands in its synopsis, e.g. new in "naval_fate ship new <name>".
+// The top level page is the only page without a parent.
+func NewSuite(pages []Page, config *Config) (*Suite, error) {
+	var all []*suitePage
+
+	for _, p := range pages {
+		all = append(all, &suitePage{
+			Page:    p,
+			tagline: ParseDocument(p.Lines).Tagline,
+			docopt:  RonnToDocoptWithConfig(p.Lines, config),
+		})
+	}
+
+	var root *suitePage
+	for _, p := range all {
+		if err := p.link(all); err != nil {
+			return nil, err
+		}
+
+		if p.parent == nil {
+			if root != nil {
+				return nil, fmt.Errorf("%s and %s are both top level pages, name subcommand pages <parent>-<command> or reference the parent in SEE ALSO", root.Name, p.Name)
+			}
+			root = p
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no top level page in the suite")
+	}
+
+	s := &Suite{Program: root.Name}
+
+	for _, p := range all {
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+
+		if p == root {
+			continue
+		}
+
+		s.Commands = append(s.Commands, SuiteCommand{
+			Path:    path,
+			Page:    p.Name,
+			Tagline: p.tagline,
+			Usage:   commandUsage(root, p, path),
+		})
+	}
+
+	sort.SliceStable(s.Commands, func(i, j int) bool {
+		return strings.Join(s.Commands[i].Path, " ") < strings.Join(s.Commands[j].Path, " ")
+	})
+
+	s.Usage = mergeUsage(root, s.Commands)
+
+	return s, nil
+}
+
+// Command returns the subcommand with the given path, e.g. "ship" or "ship new", or nil
+func (s *Suite) Command(path string) *SuiteCommand {
+	for i, c := range s.Commands {
+		if strings.Join(c.Path, " ") == path {
+			return &s.Commands[i]
+		}
+	}
+
+	return nil
+}
+
+// CommandMap maps the path of each subcommand, e.g. "ship new", to its docopt usage string,
+// so that dispatch code can parse the arguments of a subcommand with its own usage
+func (s *Suite) CommandMap() map[string]string {
+	commands := map[string]string{}
+
+	for _, c := range s.Commands {
+		commands[strings.Join(c.Path, " ")] = c.Usage.String()
+	}
+
+	return commands
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+func (p *suitePage) link(all []*suitePage) error {
+	// by name, the longest page name wins, e.g. naval_fate-ship-new is a subcommand of naval_fate-ship
+	for _, q := range all {
+		if q != p && strings.HasPrefix(p.Name, q.Name+"-") && (p.parent == nil || len(q.Name) > len(p.parent.Name)) {
+			p.parent = q
+			p.command = strings.TrimPrefix(p.Name, q.Name+"-")
+		}
+	}
+
+	if p.parent != nil {
+		return nil
+	}
+
+	// pages named after this one are its subcommands, e.g. naval_fate-ship of naval_fate,
+	// so its SEE ALSO references to them are not its parent
+	for _, q := range all {
+		if q != p && strings.HasPrefix(q.Name, p.Name+"-") {
+			return nil
+		}
+	}
+
+	seeAlso := getFirstSection(p.Lines, "SEE ALSO")
+	for _, line := range seeAlso {
+		for _, m := range pageReferenceRe.FindAllStringSubmatch(line, -1) {
+			for _, q := range all {
+				if q == p || q.Name != m[1] {
+					continue
+				}
+
+				usages, err := ParseSynopsis(p.docopt.Synopsis)
+				if err != nil || len(usages) == 0 {
+					return fmt.Errorf("%s: can't find its command in SYNOPSIS", p.Name)
+				}
+
+				commands := usages[0].Commands()
+				if len(commands) == 0 {
+					return fmt.Errorf("%s: the first line of SYNOPSIS has no command", p.Name)
+				}
+
+				p.parent = q
+				p.synopsisCommands = commands
+
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// The commands leading to the page, from the top level page
+func (p *suitePage) path() ([]string, error) {
+	return p.pathFrom(map[*suitePage]bool{})
+}
+
+func (p *suitePage) pathFrom(seen map[*suitePage]bool) ([]string, error) {
+	if p.parent == nil {
+		return nil, nil
+	}
+
+	if seen[p] {
+		return nil, fmt.Errorf("%s: the SEE ALSO references of the suite form a cycle", p.Name)
+	}
+	seen[p] = true
+
+	path, err := p.parent.pathFrom(seen)
+	if err != nil {
+		return nil, err
+	}
+
+	command := p.command
+	if command == "" {
+		// e.g. "naval_fate ship new <name>" is the new command of the ship page
+		command = p.synopsisCommands[len(p.synopsisCommands)-1]
+		if len(path) < len(p.synopsisCommands) {
+			command = p.synopsisCommands[len(path)]
+		}
+	}
+
+	return append(path, command), nil
+}
+
+// Rewrites the usage lines of a subcommand page to start with the program, e.g.
+// "naval_fate-ship new <name>" becomes "naval_fate ship new <name>"
+func suiteUsageLines(root *suitePage, p *suitePage, path []string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(p.docopt.Synopsis, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if fields[0] == "" {
+			continue
+		}
+
+		if fields[0] == p.Name {
+			fields[0] = strings.Join(append([]string{root.Name}, path...), " ")
+		}
+
+		lines = append(lines, "  "+strings.Join(fields, " "))
+	}
+
+	return lines
+}
+
+// The usage of a subcommand page, with the options of the top level page it uses, e.g. --verbose
+func commandUsage(root *suitePage, p *suitePage, path []string) *DocOpt {
+	c := *p.docopt
+	c.Synopsis = strings.Join(suiteUsageLines(root, p, path), "\n")
+	c.HelpOptionSections = append([]HelpOptionSection{}, p.docopt.HelpOptionSections...)
+
+	usages, err := ParseSynopsis(c.Synopsis)
+	if err != nil {
+		return &c
+	}
+
+	usedFlags := synopsisFlags(usages)
+
+	var global HelpOptionSection
+	for _, s := range root.docopt.HelpOptionSections {
+		for _, o := range s.Options {
+			if o.usedIn(usedFlags) && !hasOption(c.HelpOptionSections, o) {
+				global.Options = append(global.Options, o)
+			}
+		}
+	}
+
+	if len(global.Options) > 0 {
+		global.Name = fmt.Sprintf("%s options:", root.Name)
+		c.HelpOptionSections = append(c.HelpOptionSections, global)
+	}
+
+	return &c
+}
+
+// Merges the subcommand pages into the top level usage.
+// The usage lines of a subcommand replace the top level lines of that command,
+// subcommands the top level synopsis doesn't mention come before its lines without commands, e.g. --version.
+func mergeUsage(root *suitePage, commands []SuiteCommand) *DocOpt {
+	d := *root.docopt
+	d.Arguments = append([]HelpArgument{}, root.docopt.Arguments...)
+	d.HelpOptionSections = append([]HelpOptionSection{}, root.docopt.HelpOptionSections...)
+	d.Environment = append([]HelpEnvironment{}, root.docopt.Environment...)
+	d.Commands = nil
+
+	blocks := map[string][]string{}
+	var order []string
+	for _, c := range commands {
+		top := c.Path[0]
+		if _, ok := blocks[top]; !ok {
+			order = append(order, top)
+		}
+		for _, line := range strings.Split(c.Usage.Synopsis, "\n") {
+			if !containsString(blocks[top], line) {
+				blocks[top] = append(blocks[top], line)
+			}
+		}
+	}
+
+	var lines []string
+	emitted := map[string]bool{}
+	insertAt := -1
+
+	rootLines := strings.Split(root.docopt.Synopsis, "\n")
+	usages, err := ParseSynopsis(root.docopt.Synopsis)
+	if err != nil || len(usages) != len(nonBlank(rootLines)) {
+		usages = nil
+	}
+
+	for i, line := range nonBlank(rootLines) {
+		var lineCommands []string
+		if usages != nil {
+			lineCommands = usages[i].Commands()
+		}
+
+		if len(lineCommands) == 0 {
+			if insertAt == -1 {
+				insertAt = len(lines)
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		top := lineCommands[0]
+		if _, ok := blocks[top]; !ok {
+			lines = append(lines, line)
+			continue
+		}
+
+		if !emitted[top] {
+			lines = append(lines, blocks[top]...)
+			emitted[top] = true
+		}
+	}
+
+	var rest []string
+	for _, top := range order {
+		if !emitted[top] {
+			rest = append(rest, blocks[top]...)
+		}
+	}
+
+	if insertAt == -1 {
+		insertAt = len(lines)
+	}
+	lines = append(lines[:insertAt], append(rest, lines[insertAt:]...)...)
+
+	d.Synopsis = strings.Join(lines, "\n")
+
+	// documented top level commands keep their description, others use the tagline of their page
+	for _, c := range root.docopt.Commands {
+		d.Commands = append(d.Commands, HelpCommand{Name: c.Name, Desc: c.Desc})
+	}
+
+	for _, c := range commands {
+		if len(c.Path) == 1 && !hasCommand(d.Commands, c.Path[0]) {
+			d.Commands = append(d.Commands, HelpCommand{Name: c.Path[0], Desc: c.Tagline})
+		}
+
+		for _, a := range c.Usage.Arguments {
+			if !hasArgument(d.Arguments, a.Name) {
+				d.Arguments = append(d.Arguments, a)
+			}
+		}
+
+		for _, e := range c.Usage.Environment {
+			if !hasEnvironment(d.Environment, e.Name) {
+				d.Environment = append(d.Environment, e)
+			}
+		}
+
+		var section HelpOptionSection
+		for _, s := range c.Usage.HelpOptionSections {
+			for _, o := range s.Options {
+				if !hasOption(d.HelpOptionSections, o) && !hasOption([]HelpOptionSection{section}, o) {
+					section.Options = append(section.Options, o)
+				}
+			}
+		}
+
+		if len(section.Options) > 0 {
+			section.Name = fmt.Sprintf("%s options:", strings.Join(c.Path, " "))
+			d.HelpOptionSections = append(d.HelpOptionSections, section)
+		}
+	}
+
+	d.linkCommands()
+
+	return &d
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nonBlank(lines []string) []string {
+	var nonBlank []string
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonBlank = append(nonBlank, line)
+		}
+	}
+
+	return nonBlank
+}
+
+// An option is already documented when one of its flags is
+func hasOption(sections []HelpOptionSection, option HelpOption) bool {
+	flags := map[string]bool{}
+	for _, f := range optionFlags(option.Name) {
+		flags[f] = true
+	}
+
+	for _, s := range sections {
+		for _, o := range s.Options {
+			if o.usedIn(flags) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasCommand(commands []HelpCommand, name string) bool {
+	for _, c := range commands {
+		if c.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasArgument(arguments []HelpArgument, name string) bool {
+	for _, a := range arguments {
+		if a.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasEnvironment(environment []HelpEnvironment, name string) bool {
+	for _, e := range environment {
+		if e.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,182 @@
+package ronn2docopt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var suitePages = []Page{
+	{Name: "naval_fate", Lines: []string{
+		"naval_fate(6) -- the naval fate game",
+		"",
+		"## SYNOPSIS",
+		"",
+		"`naval_fate` `ship <command> [<args>...]`<br>",
+		"`naval_fate` `-h | --help`<br>",
+		"`naval_fate` `--version`",
+		"",
+		"## OPTIONS",
+		"",
+		"  * `-h`, `--help`:",
+		"    Show this screen.",
+		"  * `--version`:",
+		"    Show version.",
+		"  * `--verbose`:",
+		"    Verbose output.",
+	}},
+	{Name: "naval_fate-ship", Lines: []string{
+		"naval_fate-ship(6) -- manage ships",
+		"",
+		"## SYNOPSIS",
+		"",
+		"`naval_fate-ship` `new <name>...`<br>",
+		"`naval_fate-ship` `<name> move <x> <y> [--speed=<kn>] [--verbose]`",
+		"",
+		"## OPTIONS",
+		"",
+		"  * `--speed=<kn>`:",
+		"    Speed in knots. [default: 10]",
+	}},
+	{Name: "mine", Lines: []string{
+		"mine(6) -- manage mines",
+		"",
+		"## SYNOPSIS",
+		"",
+		"`naval_fate` `mine (set|remove) <x> <y> [--moored|--drifting]`",
+		"",
+		"## OPTIONS",
+		"",
+		"  * `--moored`:",
+		"    Moored (anchored) mine.",
+		"  * `--drifting`:",
+		"    Drifting mine.",
+		"",
+		"## SEE ALSO",
+		"",
+		"naval_fate(6)",
+	}},
+}
+
+func TestNewSuite(t *testing.T) {
+	s, err := NewSuite(suitePages, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("commands are linked by name and SEE ALSO", func(t *testing.T) {
+		var got [][]string
+		for _, c := range s.Commands {
+			got = append(got, c.Path)
+		}
+
+		want := [][]string{{"mine"}, {"ship"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("command paths got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("combined usage", func(t *testing.T) {
+		got := s.Usage.String()
+
+		want := `Usage:
+  naval_fate ship new <name>...
+  naval_fate ship <name> move <x> <y> [--speed=<kn>] [--verbose]
+  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]
+  naval_fate -h | --help
+  naval_fate --version
+
+Commands:
+  mine  manage mines
+  ship  manage ships
+
+Options:
+  -h --help  Show this screen.
+  --version  Show version.
+  --verbose  Verbose output.
+
+mine options:
+  --moored    Moored (anchored) mine.
+  --drifting  Drifting mine.
+
+ship options:
+  --speed=<kn>  Speed in knots. [default: 10]`
+
+		if got != want {
+			diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:       difflib.SplitLines(want),
+				B:       difflib.SplitLines(got),
+				Context: 3,
+			})
+			t.Errorf("\n%s", diff)
+		}
+	})
+
+	t.Run("command map has the usage of each subcommand", func(t *testing.T) {
+		got := s.CommandMap()["ship"]
+
+		want := `Usage:
+  naval_fate ship new <name>...
+  naval_fate ship <name> move <x> <y> [--speed=<kn>] [--verbose]
+
+Options:
+  --speed=<kn>  Speed in knots. [default: 10]
+
+naval_fate options:
+  --verbose  Verbose output.`
+
+		if got != want {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when the top level page references its subcommands in SEE ALSO", func(t *testing.T) {
+		root := Page{Name: "naval_fate", Lines: append(suitePages[0].Lines[:len(suitePages[0].Lines):len(suitePages[0].Lines)],
+			"",
+			"## SEE ALSO",
+			"",
+			"naval_fate-ship(6), mine(6)",
+		)}
+
+		s, err := NewSuite(append([]Page{root}, suitePages[1:]...), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.Program != "naval_fate" {
+			t.Errorf("program got = %s, want naval_fate", s.Program)
+		}
+
+		var got [][]string
+		for _, c := range s.Commands {
+			got = append(got, c.Path)
+		}
+
+		want := [][]string{{"mine"}, {"ship"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("command paths got = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("when a page has no parent", func(t *testing.T) {
+		_, err := NewSuite(append(suitePages[:1:1], Page{Name: "other", Lines: []string{"## SYNOPSIS", "", "`other`"}}), nil)
+
+		want := "naval_fate and other are both top level pages, name subcommand pages <parent>-<command> or reference the parent in SEE ALSO"
+		if err == nil || err.Error() != want {
+			t.Errorf("error got = %v, want %s", err, want)
+		}
+	})
+}
+
+func TestPageName(t *testing.T) {
+	got := PageName("docs/naval_fate-ship.1.ronn", []string{"## SYNOPSIS"})
+	if got != "naval_fate-ship" {
+		t.Errorf("got = %s, want naval_fate-ship", got)
+	}
+
+	got = PageName("docs/ship.ronn", []string{"naval_fate-ship(1) -- manage ships"})
+	if got != "naval_fate-ship" {
+		t.Errorf("got = %s, want naval_fate-ship", got)
+	}
+}
@@ -0,0 +1,264 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type PatternKind int
+
+const (
+	CommandPattern PatternKind = iota
+	ArgumentPattern
+	OptionPattern
+	OptionsShortcutPattern
+	SequencePattern
+	RequiredPattern
+	OptionalPattern
+	EitherPattern
+)
+
+// A Pattern is a node in the synopsis tree.
+// Leaves (commands, arguments, options) have a Name,
+// groups (sequence, required, optional, either) have Children.
+type Pattern struct {
+	Kind     PatternKind
+	Name     string
+	Repeated bool
+	Children []*Pattern
+}
+
+// A UsageLine is a single line of the synopsis, e.g.
+//
+//	naval_fate ship <name> move <x> <y> [--speed=<kn>]
+type UsageLine struct {
+	Program string
+	Text    string
+	Pattern *Pattern
+}
+
+var synopsisTokenRe = regexp.MustCompile(`\.\.\.|[\[\]()|]|[^\s\[\]()|]+`)
+var upperArgumentRe = regexp.MustCompile(`^[A-Z][A-Z0-9_-]*$`)
+
+// ParseSynopsis parses a formatted synopsis (see formatSynopsis) into a tree per usage line
+func ParseSynopsis(synopsis string) ([]UsageLine, error) {
+	var usages []UsageLine
+
+	for _, line := range strings.Split(synopsis, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		tokens := tokenizeSynopsis(line)
+
+		p := &synopsisParser{tokens: tokens[1:]}
+		pattern, err := p.parseExpr()
+		if err == nil && p.pos < len(p.tokens) {
+			err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("synopsis line %q: %s", line, err)
+		}
+
+		usages = append(usages, UsageLine{
+			Program: tokens[0],
+			Text:    strings.TrimSpace(strings.TrimPrefix(line, tokens[0])),
+			Pattern: pattern,
+		})
+	}
+
+	return usages, nil
+}
+
+// Commands returns the command path at the start of the usage line.
+// e.g. "ship new <name>..." returns [ship new]
+func (u *UsageLine) Commands() []string {
+	var commands []string
+
+	children := []*Pattern{u.Pattern}
+	if u.Pattern.Kind == SequencePattern {
+		children = u.Pattern.Children
+	}
+
+	for _, c := range children {
+		if c.Kind != CommandPattern {
+			break
+		}
+
+		commands = append(commands, c.Name)
+	}
+
+	return commands
+}
+
+// Leaves returns all the leaves of the given kind, depth first
+func (p *Pattern) Leaves(kind PatternKind) []*Pattern {
+	var leaves []*Pattern
+
+	if p.Kind == kind && len(p.Children) == 0 {
+		leaves = append(leaves, p)
+	}
+
+	for _, c := range p.Children {
+		leaves = append(leaves, c.Leaves(kind)...)
+	}
+
+	return leaves
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+type synopsisParser struct {
+	tokens []string
+	pos    int
+}
+
+// Splits a usage line into tokens, separating brackets, pipes and ellipses
+func tokenizeSynopsis(line string) []string {
+	var tokens []string
+
+	for _, t := range synopsisTokenRe.FindAllString(line, -1) {
+		if t != "..." && strings.HasSuffix(t, "...") {
+			tokens = append(tokens, strings.TrimSuffix(t, "..."), "...")
+			continue
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
+
+func (p *synopsisParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+// expr ::= seq ( "|" seq )*
+func (p *synopsisParser) parseExpr() (*Pattern, error) {
+	seq, err := p.parseSeq()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != "|" {
+		return seq, nil
+	}
+
+	either := &Pattern{Kind: EitherPattern, Children: []*Pattern{seq}}
+
+	for p.peek() == "|" {
+		p.pos++
+
+		seq, err = p.parseSeq()
+		if err != nil {
+			return nil, err
+		}
+
+		either.Children = append(either.Children, seq)
+	}
+
+	return either, nil
+}
+
+// seq ::= ( atom [ "..." ] )*
+func (p *synopsisParser) parseSeq() (*Pattern, error) {
+	seq := &Pattern{Kind: SequencePattern}
+
+	for {
+		t := p.peek()
+		if t == "" || t == "]" || t == ")" || t == "|" {
+			break
+		}
+
+		atom, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() == "..." {
+			atom.Repeated = true
+			p.pos++
+		}
+
+		seq.Children = append(seq.Children, atom)
+	}
+
+	return seq, nil
+}
+
+// atom ::= "(" expr ")" | "[" expr "]" | "[options]" | leaf
+func (p *synopsisParser) parseAtom() (*Pattern, error) {
+	t := p.peek()
+	p.pos++
+
+	switch t {
+	case "(", "[":
+		closing, kind := ")", RequiredPattern
+		if t == "[" {
+			closing, kind = "]", OptionalPattern
+		}
+
+		if t == "[" && p.peek() == "options" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "]" {
+			p.pos += 2
+			return &Pattern{Kind: OptionsShortcutPattern, Name: "options"}, nil
+		}
+
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != closing {
+			return nil, fmt.Errorf("missing %q", closing)
+		}
+		p.pos++
+
+		return &Pattern{Kind: kind, Children: []*Pattern{expr}}, nil
+	case "...":
+		return nil, fmt.Errorf("unexpected %q", t)
+	}
+
+	return newLeafPattern(t), nil
+}
+
+func newLeafPattern(token string) *Pattern {
+	switch {
+	case strings.HasPrefix(token, "-") && token != "-" && token != "--":
+		return &Pattern{Kind: OptionPattern, Name: token}
+	case strings.HasPrefix(token, "<") && strings.HasSuffix(token, ">"):
+		return &Pattern{Kind: ArgumentPattern, Name: token}
+	case upperArgumentRe.MatchString(token):
+		return &Pattern{Kind: ArgumentPattern, Name: token}
+	}
+
+	return &Pattern{Kind: CommandPattern, Name: token}
+}
+
+// Returns the flags of an option name, without any argument placeholder
+// e.g. "-s <kn> --speed=<kn>" returns [-s --speed]
+func optionFlags(name string) []string {
+	var flags []string
+
+	for _, f := range strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if !strings.HasPrefix(f, "-") {
+			continue
+		}
+
+		if i := strings.IndexAny(f, "=["); i > 0 {
+			f = f[:i]
+		}
+
+		flags = append(flags, f)
+	}
+
+	return flags
+}
@@ -0,0 +1,123 @@
+package ronn2docopt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var navalFateSynopsis = "  naval_fate ship new <name>...\n" +
+	"  naval_fate ship <name> move <x> <y> [--speed=<kn>]\n" +
+	"  naval_fate ship shoot <x> <y>\n" +
+	"  naval_fate mine (set|remove) <x> <y> [--moored|--drifting]\n" +
+	"  naval_fate -h | --help\n" +
+	"  naval_fate --version"
+
+func TestParseSynopsis(t *testing.T) {
+	t.Run("parses one usage line per synopsis line", func(t *testing.T) {
+		usages, err := ParseSynopsis(navalFateSynopsis)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := len(usages)
+		want := 6
+		if got != want {
+			t.Errorf("number of usage lines got = %d, want %d", got, want)
+		}
+
+		if usages[1].Program != "naval_fate" {
+			t.Errorf("program got = %s, want naval_fate", usages[1].Program)
+		}
+
+		if usages[1].Text != "ship <name> move <x> <y> [--speed=<kn>]" {
+			t.Errorf("text got = %s", usages[1].Text)
+		}
+	})
+
+	t.Run("when argument is repeated", func(t *testing.T) {
+		usages, _ := ParseSynopsis("naval_fate ship new <name>...")
+
+		leaves := usages[0].Pattern.Leaves(ArgumentPattern)
+		if len(leaves) != 1 {
+			t.Fatalf("number of arguments got = %d, want 1", len(leaves))
+		}
+
+		if leaves[0].Name != "<name>" || !leaves[0].Repeated {
+			t.Errorf("argument got = %+v, want repeated <name>", leaves[0])
+		}
+	})
+
+	t.Run("when has alternatives in an optional group", func(t *testing.T) {
+		usages, _ := ParseSynopsis("naval_fate mine (set|remove) <x> <y> [--moored|--drifting]")
+
+		children := usages[0].Pattern.Children
+		optional := children[len(children)-1]
+		if optional.Kind != OptionalPattern {
+			t.Fatalf("kind got = %d, want %d", optional.Kind, OptionalPattern)
+		}
+
+		either := optional.Children[0]
+		if either.Kind != EitherPattern || len(either.Children) != 2 {
+			t.Errorf("optional group got = %+v, want either with 2 alternatives", either)
+		}
+
+		var got []string
+		for _, leaf := range optional.Leaves(OptionPattern) {
+			got = append(got, leaf.Name)
+		}
+
+		want := []string{"--moored", "--drifting"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("options got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("when has options shortcut", func(t *testing.T) {
+		usages, _ := ParseSynopsis("naval_fate [options] FILE")
+
+		children := usages[0].Pattern.Children
+		if children[0].Kind != OptionsShortcutPattern {
+			t.Errorf("kind got = %d, want %d", children[0].Kind, OptionsShortcutPattern)
+		}
+
+		if children[1].Kind != ArgumentPattern {
+			t.Errorf("kind got = %d, want %d", children[1].Kind, ArgumentPattern)
+		}
+	})
+
+	t.Run("when brackets are unbalanced", func(t *testing.T) {
+		_, err := ParseSynopsis("naval_fate ship [--speed=<kn>")
+		if err == nil {
+			t.Error("error got = nil, want error")
+		}
+	})
+}
+
+func TestUsageLine_Commands(t *testing.T) {
+	usages, _ := ParseSynopsis(navalFateSynopsis)
+
+	want := [][]string{
+		{"ship", "new"},
+		{"ship"},
+		{"ship", "shoot"},
+		{"mine"},
+		nil,
+		nil,
+	}
+
+	for i, u := range usages {
+		got := u.Commands()
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("commands of %q got = %v, want %v", u.Text, got, want[i])
+		}
+	}
+}
+
+func TestOptionFlags(t *testing.T) {
+	got := optionFlags("-s <kn>, --speed=<kn>")
+	want := []string{"-s", "--speed"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+}
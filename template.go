@@ -0,0 +1,176 @@
+package ronn2docopt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// TemplateFuncs are the helper functions of custom templates, e.g.
+//
+//	{{range .Options}}{{.Name | pad 20}}{{.Desc | plain | wrap 60 | indent 2}}{{end}}
+var TemplateFuncs = template.FuncMap{
+	// pad 20 "--speed" pads the value with spaces to 20 characters, see PadRight
+	"pad": func(length int, s string) string { return PadRight(s, " ", length) },
+
+	// wrap 60 "..." wraps the words of the value into lines of at most 60 characters
+	"wrap": wrapText,
+
+	// indent 2 "..." indents every line of the value by 2 spaces
+	"indent": func(n int, s string) string {
+		return strings.Repeat(" ", n) + strings.Replace(s, "\n", "\n"+strings.Repeat(" ", n), -1)
+	},
+
+	// plain "`--speed` knots" removes the inline markup, see PlainInline
+	"plain": PlainInline,
+
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+	"trim":   strings.TrimSpace,
+	"join":   func(sep string, items []string) string { return strings.Join(items, sep) },
+	"snake":  func(s string) string { return strings.Join(caseWords(s, strings.ToLower), "_") },
+	"kebab":  func(s string) string { return strings.Join(caseWords(s, strings.ToLower), "-") },
+	"pascal": func(s string) string { return strings.Join(caseWords(s, title), "") },
+	"camel": func(s string) string {
+		words := caseWords(s, title)
+		if len(words) > 0 {
+			words[0] = strings.ToLower(words[0])
+		}
+
+		return strings.Join(words, "")
+	},
+}
+
+// TemplateData is what custom templates are executed over.
+// The DocOpt model is embedded, e.g. {{.Synopsis}} and {{range .HelpOptionSections}},
+// Options are the options of every section, and Name, Section and Tagline come from the title line of the page.
+type TemplateData struct {
+	*DocOpt
+	Document *Document
+	Usage    string
+	Options  []HelpOption
+	Name     string
+	Section  string
+	Tagline  string
+}
+
+// A TemplateRenderer renders pages with a text/template, see TemplateFuncs for the helper functions
+type TemplateRenderer struct {
+	Template *template.Template
+}
+
+// NewTemplateRenderer parses the text of a template, name is used in its errors
+func NewTemplateRenderer(name string, text string) (*TemplateRenderer, error) {
+	t, err := template.New(name).Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateRenderer{Template: t}, nil
+}
+
+// ReadTemplateFile reads and parses a template file, e.g. makefile-help.tmpl
+func ReadTemplateFile(templateFile string) (*TemplateRenderer, error) {
+	text, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTemplateRenderer(filepath.Base(templateFile), string(text))
+}
+
+func (r *TemplateRenderer) Render(page *ParsedPage, options RenderOptions) ([]byte, error) {
+	data := TemplateData{
+		DocOpt:   page.DocOpt,
+		Document: page.Document,
+		Usage:    page.DocOpt.String(),
+		Name:     page.Document.Name,
+		Section:  page.Document.ManSection,
+		Tagline:  page.Document.Tagline,
+	}
+
+	for _, s := range page.DocOpt.HelpOptionSections {
+		data.Options = append(data.Options, s.Options...)
+	}
+
+	var buffer bytes.Buffer
+	if err := r.Template.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// ==================================================== //
+// PRIVATE METHODS
+// ---------------------------------------------------- //
+
+// Greedily fills lines of at most width characters, words longer than width get a line of their own
+func wrapText(width int, s string) string {
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Splits a name into its words, e.g. "--dry-run", "<file_name>" and "DryRun" are all [dry run],
+// and applies the case to each of them
+func caseWords(s string, wordCase func(string) string) []string {
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, wordCase(string(word)))
+			word = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		// a new word starts at an upper case letter after a lower case one, e.g. dryRun,
+		// or before a lower case one at the end of an acronym, e.g. HTTPServer
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+		}
+
+		word = append(word, r)
+	}
+	flush()
+
+	return words
+}
+
+// e.g. dry returns Dry
+func title(s string) string {
+	runes := []rune(strings.ToLower(s))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+
+	return string(runes)
+}
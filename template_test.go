@@ -0,0 +1,105 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func TestTemplateRenderer_Render(t *testing.T) {
+	lines := []string{
+		"thing(1) -- do the thing",
+		"========================",
+		"",
+		"## SYNOPSIS",
+		"",
+		"`thing` `[options] <file>`",
+		"",
+		"## OPTIONS",
+		"",
+		"  * `--dry-run`:",
+		"    Show what would be done, without **doing** it, for every file given.",
+		"  * `--speed=<kn>`:",
+		"    Speed in knots. [default: 10]",
+		"",
+	}
+
+	text := `{{.Name | upper}}({{.Section}}): {{.Tagline}}
+{{range .Options}}{{.Name | pad 14}}{{.Desc | plain | wrap 30 | indent 14 | trim}}
+{{end}}{{range .Options}}{{.Name | snake}} {{.Name | kebab}} {{.Name | camel}} {{.Name | pascal}}
+{{end}}{{.Usage}}
+`
+
+	r, err := NewTemplateRenderer("help", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.Render(ParsePage(lines, nil), RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "THING(1): do the thing\n" +
+		"--dry-run     Show what would be done,\n" +
+		"              without doing it, for every\n" +
+		"              file given.\n" +
+		"--speed=<kn>  Speed in knots.\n" +
+		"dry_run dry-run dryRun DryRun\n" +
+		"speed_kn speed-kn speedKn SpeedKn\n" +
+		"Usage:\n" +
+		"  thing [options] <file>\n" +
+		"\n" +
+		"Options:\n" +
+		"  --dry-run     Show what would be done, without doing it, for every file given.\n" +
+		"  --speed=<kn>  Speed in knots. [default: 10]\n"
+
+	if string(got) != want {
+		diff := difflib.UnifiedDiff{
+			A:       difflib.SplitLines(want),
+			B:       difflib.SplitLines(string(got)),
+			Context: 3,
+		}
+		text, _ := difflib.GetUnifiedDiffString(diff)
+
+		fmt.Println(text)
+		t.Error()
+	}
+
+	t.Run("when the template doesn't parse", func(t *testing.T) {
+		if _, err := NewTemplateRenderer("help", "{{.Name"); err == nil {
+			t.Error("err got = nil, want an error")
+		}
+	})
+
+	t.Run("when the template fails", func(t *testing.T) {
+		r, err := NewTemplateRenderer("help", "{{.Nope}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.Render(ParsePage(lines, nil), RenderOptions{}); err == nil {
+			t.Error("err got = nil, want an error")
+		}
+	})
+}
+
+func TestCaseWords(t *testing.T) {
+	tests := map[string]string{
+		"--dry-run":   "[dry run]",
+		"<file_name>": "[file name]",
+		"DryRun":      "[dry run]",
+		"HTTPServer":  "[http server]",
+		"-v":          "[v]",
+	}
+
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			if got := fmt.Sprint(caseWords(in, strings.ToLower)); got != want {
+				t.Errorf("caseWords(%q) got = %s, want %s", in, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package a
+
+import "github.com/docopt/docopt-go"
+
+//ronn2docopt:source docs/naval_fate.1.ronn
+const upToDate = `Usage:
+  naval_fate ship new <name>...
+  naval_fate --version
+
+Options:
+  --version  Show version.`
+
+//ronn2docopt:source docs/other.1.ronn
+const handEdited = /* want `usage string differs from other.1.ronn: line 5 is "  --version  Show the version.", want "  --version  Show version."` */ `
+Usage:
+  other --version
+
+Options:
+  --version  Show the version.
+`
+
+const usage = "Usage:\n  naval_fate --version\n" // want `usage string differs from naval_fate.1.ronn: line 2 is "  naval_fate --version", want "  naval_fate ship new <name>..."`
+
+const unrelated = "Usage: unknown"
+
+func main() {
+	docopt.Parse(upToDate, nil, true, "", false)
+	docopt.Parse(usage, nil, true, "", false)
+	docopt.Parse(unrelated, nil, true, "", false)
+}
@@ -0,0 +1,5 @@
+package docopt
+
+func Parse(doc string, argv []string, help bool, version string, optionsFirst bool, exit ...bool) (map[string]interface{}, error) {
+	return nil, nil
+}
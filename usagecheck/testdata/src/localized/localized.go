@@ -0,0 +1,12 @@
+package localized
+
+//ronn2docopt:source docs/naval_fate.1.ronn
+const upToDate = `Usage:
+  naval_fate --version
+
+Options:
+  --version  Version anzeigen.`
+
+//ronn2docopt:source docs/naval_fate.1.ronn
+const handEdited = /* want `usage string differs from naval_fate.1.ronn: line 4 is "", want "Options:"` */ `Usage:
+  naval_fate --version`
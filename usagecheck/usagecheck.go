@@ -0,0 +1,286 @@
+// Package usagecheck defines an analyzer that checks docopt usage strings against the ronn pages they are generated from.
+//
+// A usage string is checked when it's marked with a source comment, relative to the directory of the Go file:
+//
+//	//ronn2docopt:source docs/naval_fate.1.ronn
+//	const usage = `...`
+//
+// or when it's passed to docopt.Parse, and docs/<program>.<section>.ronn exists next to the Go file,
+// where program is the first word of the usage line.
+package usagecheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghostsquad/ronn2docopt"
+	"golang.org/x/tools/go/analysis"
+)
+
+const sourceDirective = "//ronn2docopt:source "
+
+const docoptPackage = "github.com/docopt/docopt-go"
+
+var Analyzer = NewAnalyzer(nil)
+
+// NewAnalyzer returns an analyzer that reads the ronn pages with the section names of the config,
+// see ronn2docopt.RonnToDocoptWithConfig. Its -config flag reads them from a file instead, see ronn2docopt.ReadConfigFile.
+func NewAnalyzer(config *ronn2docopt.Config) *analysis.Analyzer {
+	a := &analysis.Analyzer{
+		Name: "ronn2docopt",
+		Doc:  "check that docopt usage strings match the ronn pages they are generated from",
+	}
+
+	configFile := a.Flags.String("config", "", "file of the section names and headings of localized ronn pages")
+
+	a.Run = func(pass *analysis.Pass) (interface{}, error) {
+		if *configFile == "" {
+			return run(pass, config)
+		}
+
+		c, err := ronn2docopt.ReadConfigFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return run(pass, c)
+	}
+
+	return a
+}
+
+var usageProgramRe = regexp.MustCompile(`(?im)^\s*usage:\s*(?:\n\s*)?([\w.-]+)`)
+
+// a string constant, and the expression it's declared with
+type usageString struct {
+	value  string
+	expr   ast.Expr
+	source string
+}
+
+func run(pass *analysis.Pass, config *ronn2docopt.Config) (interface{}, error) {
+	declared := map[types.Object]*usageString{}
+	checked := map[ast.Expr]bool{}
+
+	for _, file := range pass.Files {
+		dir := filepath.Dir(pass.Fset.Position(file.Pos()).Filename)
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+
+				source := sourceComment(vs.Doc)
+				if source == "" && len(gen.Specs) == 1 {
+					source = sourceComment(gen.Doc)
+				}
+				if source != "" && !filepath.IsAbs(source) {
+					source = filepath.Join(dir, source)
+				}
+
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						break
+					}
+
+					value, ok := stringValue(pass, vs.Values[i])
+					if !ok {
+						continue
+					}
+
+					u := &usageString{value: value, expr: vs.Values[i], source: source}
+					declared[pass.TypesInfo.Defs[name]] = u
+
+					if source != "" {
+						check(pass, u, config)
+						checked[u.expr] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range pass.Files {
+		dir := filepath.Dir(pass.Fset.Position(file.Pos()).Filename)
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isDocoptParse(pass, call) || len(call.Args) == 0 {
+				return true
+			}
+
+			u := usageArgument(pass, declared, call.Args[0])
+			if u == nil || checked[u.expr] {
+				return true
+			}
+			checked[u.expr] = true
+
+			if u.source == "" {
+				u.source = conventionalSource(dir, u.value)
+			}
+
+			if u.source != "" {
+				check(pass, u, config)
+			}
+
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+// Reports the usage string when it differs from the usage generated from its ronn page
+func check(pass *analysis.Pass, u *usageString, config *ronn2docopt.Config) {
+	lines, _, err := ronn2docopt.ReadRonnFileWithIncludes(u.source)
+	if err != nil {
+		pass.Reportf(u.expr.Pos(), "can't read the ronn source of the usage string: %s", err)
+		return
+	}
+
+	want := ronn2docopt.RonnToDocoptWithConfig(lines, config).String()
+	got := strings.TrimSpace(u.value)
+	if got == want {
+		return
+	}
+
+	// keep the leading and trailing whitespace of the original, e.g. a trailing newline
+	trimmed := strings.TrimLeft(u.value, " \t\n")
+	leading := u.value[:len(u.value)-len(trimmed)]
+	trailing := trimmed[len(strings.TrimRight(trimmed, " \t\n")):]
+
+	pass.Report(analysis.Diagnostic{
+		Pos:     u.expr.Pos(),
+		End:     u.expr.End(),
+		Message: fmt.Sprintf("usage string differs from %s: %s", filepath.Base(u.source), firstDifference(got, want)),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Regenerate the usage string from " + filepath.Base(u.source),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     u.expr.Pos(),
+				End:     u.expr.End(),
+				NewText: []byte(stringLiteral(leading + want + trailing)),
+			}},
+		}},
+	})
+}
+
+// e.g. //ronn2docopt:source docs/naval_fate.1.ronn
+func sourceComment(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, sourceDirective) {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, sourceDirective))
+		}
+	}
+
+	return ""
+}
+
+func stringValue(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+
+	return constant.StringVal(tv.Value), true
+}
+
+// docopt.Parse, and the other functions of docopt-go that take a usage string first
+func isDocoptParse(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != docoptPackage {
+		return false
+	}
+
+	switch fn.Name() {
+	case "Parse", "ParseDoc", "ParseArgs":
+		return true
+	}
+
+	return false
+}
+
+// The usage string passed to docopt.Parse, a constant or a literal
+func usageArgument(pass *analysis.Pass, declared map[types.Object]*usageString, arg ast.Expr) *usageString {
+	var obj types.Object
+	switch a := arg.(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[a]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[a.Sel]
+	}
+
+	if obj != nil {
+		return declared[obj]
+	}
+
+	if value, ok := stringValue(pass, arg); ok {
+		return &usageString{value: value, expr: arg}
+	}
+
+	return nil
+}
+
+// docs/<program>.<section>.ronn, next to the Go file
+func conventionalSource(dir string, usage string) string {
+	m := usageProgramRe.FindStringSubmatch(usage)
+	if m == nil {
+		return ""
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "docs", m[1]+".*.ronn"))
+	if len(matches) == 0 {
+		return ""
+	}
+
+	return matches[0]
+}
+
+func firstDifference(got string, want string) string {
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(want, "\n")
+
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+
+		if g != w {
+			return fmt.Sprintf("line %d is %q, want %q", i+1, g, w)
+		}
+	}
+
+	return "whitespace differs"
+}
+
+// A raw string literal when possible, as usage strings are usually written
+func stringLiteral(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
@@ -0,0 +1,22 @@
+package usagecheck_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ghostsquad/ronn2docopt/usagecheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), usagecheck.Analyzer, "a")
+}
+
+func TestAnalyzer_Config(t *testing.T) {
+	analyzer := usagecheck.NewAnalyzer(nil)
+	if err := analyzer.Flags.Set("config", filepath.Join(analysistest.TestData(), "src", "localized", "sections.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), analyzer, "localized")
+}
@@ -0,0 +1,112 @@
+package ronn2docopt
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// e.g. {{.Version}} or {{ .ProgName }}
+var varRe = regexp.MustCompile(`\{\{\s*\.([A-Za-z_]\w*)\s*\}\}`)
+var varNameRe = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// Vars are the values of the template variables of a ronn page, by name, e.g. Version
+type Vars map[string]string
+
+// ParseSetting sets a name=value variable, e.g. Version=1.2.0
+func (v Vars) ParseSetting(setting string) error {
+	parts := strings.SplitN(setting, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid variable %q, want <name>=<value>", setting)
+	}
+
+	name := strings.TrimSpace(parts[0])
+	if !varNameRe.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+
+	v[name] = parts[1]
+
+	return nil
+}
+
+// ReadVarsFile reads name=value variables, one per line. Blank lines and lines starting with # are ignored.
+func ReadVarsFile(varsFile string) (Vars, error) {
+	file, err := os.Open(varsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, err := ReadLines(NewLineScanner(file, DefaultMaxLineLength))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", varsFile, err)
+	}
+
+	vars := Vars{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := vars.ParseSetting(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", varsFile, i+1, err)
+		}
+	}
+
+	return vars, nil
+}
+
+// VarEnvName is the environment variable a template variable is looked up in, e.g. ProgName is read from RONN_PROG_NAME
+func VarEnvName(name string) string {
+	var buffer strings.Builder
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			buffer.WriteRune('_')
+		}
+
+		buffer.WriteRune(unicode.ToUpper(r))
+	}
+
+	return "RONN_" + buffer.String()
+}
+
+// SubstituteVars replaces the template variables of a ronn page, e.g. {{.Version}}, with their values.
+// Variables missing from vars are looked up in the environment with lookup (see VarEnvName), when it is not nil.
+// Each variable that is still undefined is reported, and left as is.
+func SubstituteVars(lines []string, vars Vars, lookup func(string) (string, bool)) ([]string, []Diagnostic) {
+	var substituted []string
+	var undefined []Diagnostic
+
+	for i, line := range lines {
+		line = varRe.ReplaceAllStringFunc(line, func(v string) string {
+			name := varRe.FindStringSubmatch(v)[1]
+
+			if value, ok := vars[name]; ok {
+				return value
+			}
+
+			if lookup != nil {
+				if value, ok := lookup(VarEnvName(name)); ok {
+					return value
+				}
+			}
+
+			undefined = append(undefined, Diagnostic{
+				Line:    i + 1,
+				Message: fmt.Sprintf("undefined variable %s", name),
+			})
+
+			return v
+		})
+
+		substituted = append(substituted, line)
+	}
+
+	return substituted, undefined
+}
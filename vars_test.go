@@ -0,0 +1,110 @@
+package ronn2docopt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSubstituteVars(t *testing.T) {
+	lines := []string{
+		"{{.ProgName}}(1) -- version {{ .Version }}",
+		"",
+		"  * `--port=<port>`:",
+		"    Port to listen on. [default: {{.DefaultPort}}]",
+	}
+
+	t.Run("when all variables are defined", func(t *testing.T) {
+		vars := Vars{"ProgName": "naval_fate", "Version": "1.2.0"}
+		env := map[string]string{"RONN_DEFAULT_PORT": "8080", "RONN_VERSION": "0.0.0"}
+
+		got, undefined := SubstituteVars(lines, vars, func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		})
+
+		want := []string{
+			"naval_fate(1) -- version 1.2.0",
+			"",
+			"  * `--port=<port>`:",
+			"    Port to listen on. [default: 8080]",
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %q, want %q", got, want)
+		}
+
+		if len(undefined) != 0 {
+			t.Errorf("undefined got = %v, want none", undefined)
+		}
+	})
+
+	t.Run("when variables are undefined", func(t *testing.T) {
+		got, undefined := SubstituteVars(lines, Vars{"ProgName": "naval_fate"}, nil)
+
+		var messages []string
+		for _, d := range undefined {
+			messages = append(messages, d.String())
+		}
+
+		want := []string{"1: undefined variable Version", "4: undefined variable DefaultPort"}
+		if !reflect.DeepEqual(messages, want) {
+			t.Errorf("undefined got = %q, want %q", messages, want)
+		}
+
+		if got[3] != lines[3] {
+			t.Errorf("line got = %q, want it unchanged", got[3])
+		}
+	})
+}
+
+func TestVarEnvName(t *testing.T) {
+	tests := map[string]string{
+		"Version":     "RONN_VERSION",
+		"ProgName":    "RONN_PROG_NAME",
+		"DefaultPort": "RONN_DEFAULT_PORT",
+		"HTTPPort":    "RONN_HTTP_PORT",
+	}
+
+	for in, want := range tests {
+		got := VarEnvName(in)
+		if got != want {
+			t.Errorf("VarEnvName(%q) got = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestReadVarsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ronn2docopt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "vars")
+	if err := ioutil.WriteFile(file, []byte("# build settings\nVersion=1.2.0\n\nDefaultPort = 8080\nnope\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReadVarsFile(file)
+	want := file + ":5: invalid variable \"nope\", want <name>=<value>"
+	if err == nil || err.Error() != want {
+		t.Fatalf("error got = %v, want %s", err, want)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("# build settings\nVersion=1.2.0\n\nDefaultPort =8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadVarsFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantVars := Vars{"Version": "1.2.0", "DefaultPort": "8080"}
+	if !reflect.DeepEqual(got, wantVars) {
+		t.Errorf("got = %v, want %v", got, wantVars)
+	}
+}